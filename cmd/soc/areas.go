@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jcorbin/soc/internal/isotime"
+	"github.com/jcorbin/soc/internal/socui"
+)
+
+func init() {
+	builtinServer("areas", serveAreas,
+		"list all [areas] referenced in the stream, most recently active first")
+	builtinServer("area", serveArea,
+		"print the history of remnant (e.g. Done) items within an [area]")
+}
+
+func serveAreas(ctx *context, req *socui.Request, res *socui.Response) error {
+	if err := ctx.today.open(ctx.store); errors.Is(err, errStoreNotExists) {
+		return errors.New("no areas found, stream is empty")
+	} else if err != nil {
+		return err
+	}
+
+	type areaStat struct {
+		name string
+		days int
+		last isotime.GrainedTime
+		day  isotime.GrainedTime // last day counted in days
+	}
+	var (
+		stats []areaStat
+		index = make(map[string]int)
+		areas [][]byte
+	)
+	sc := &ctx.today.sc
+	for sc.Scan() {
+		if !sc.titled {
+			continue
+		}
+		t := truncateDay(sc.lastTime())
+		areas = sc.areas(areas[:0], len(sc.title)-1)
+		for _, area := range areas {
+			key := strings.ToLower(string(area))
+			i, seen := index[key]
+			if !seen {
+				i = len(stats)
+				index[key] = i
+				stats = append(stats, areaStat{name: string(area)})
+			}
+			stat := &stats[i]
+			if t.Grain() == 0 {
+				continue
+			}
+			if stat.day.Grain() == 0 || !stat.day.Equal(t) {
+				stat.day = t
+				stat.days++
+			}
+			if stat.last.Grain() == 0 || t.After(stat.last) {
+				stat.last = t
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	if len(stats) == 0 {
+		return errors.New("no areas found")
+	}
+
+	sort.SliceStable(stats, func(i, j int) bool {
+		return stats[i].last.Start().After(stats[j].last.Start())
+	})
+	res.Break()
+	for _, stat := range stats {
+		if stat.last.Grain() == 0 {
+			fmt.Fprintf(res, "[%v]\n", stat.name)
+			continue
+		}
+		days := "days"
+		if stat.days == 1 {
+			days = "day"
+		}
+		fmt.Fprintf(res, "[%v] last active %v, on %v %v\n", stat.name, stat.last, stat.days, days)
+	}
+	return nil
+}
+
+func serveArea(ctx *context, req *socui.Request, res *socui.Response) error {
+	args := scanArgs(req)
+	if len(args) != 1 {
+		return fmt.Errorf("usage: %v <name>", ctx.Command())
+	}
+	name := strings.TrimSuffix(strings.TrimPrefix(args[0], "["), "]")
+	if name == "" {
+		return fmt.Errorf("usage: %v <name>", ctx.Command())
+	}
+
+	if err := ctx.today.open(ctx.store); errors.Is(err, errStoreNotExists) {
+		return fmt.Errorf("no items found within [%v], stream is empty", name)
+	} else if err != nil {
+		return err
+	}
+
+	var filter outlineFilter = outlineAreaFilter(name)
+	what := "items"
+	if i := ctx.today.remnantSection(); i >= 0 {
+		filter = outlineFilterAnd{filter, ctx.today.sectionFilter(i)}
+		what = ctx.today.sectionNames[i] + " items"
+	}
+	found := false
+	if err := ctx.today.sc.printOutline(res, outlineFilterFunc(func(out *outline) bool {
+		if filter.match(out) {
+			found = true
+			return true
+		}
+		return false
+	})); err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("no %v found within [%v]", what, name)
+	}
+	return nil
+}
+
+// areas appends the labels of any shortcut reference links, like "[scanio]",
+// within the i-th title. Such references are usually left dangling, and serve
+// to group items by area of concern. Links that are followed by a destination,
+// label, or definition colon are not shortcut references, nor are task list
+// check boxes like "[ ]" or "[x]".
+func (out *outline) areas(into [][]byte, i int) [][]byte {
+	b, _ := out.title[i].Bytes()
+	for j := 0; j < len(b); j++ {
+		if b[j] != '[' {
+			continue
+		}
+		if j > 0 {
+			if c := b[j-1]; c == '!' || c == '\\' || c == ']' {
+				continue
+			}
+		}
+		k := bytes.IndexAny(b[j+1:], "[]")
+		if k < 0 {
+			break
+		}
+		k += j + 1
+		if b[k] == '[' {
+			j = k - 1
+			continue
+		}
+		label := bytes.TrimSpace(b[j+1 : k])
+		j = k
+		if len(label) == 0 || bytes.EqualFold(label, []byte("x")) {
+			continue
+		}
+		if k+1 < len(b) {
+			if c := b[k+1]; c == '(' || c == '[' || c == ':' {
+				continue
+			}
+		}
+		into = append(into, label)
+	}
+	return into
+}
+
+// outlineAreaFilter matches outline items within, or themselves referencing,
+// an [area].
+type outlineAreaFilter string
+
+func (name outlineAreaFilter) match(out *outline) bool {
+	var buf [4][]byte
+	for i := range out.title {
+		for _, area := range out.areas(buf[:0], i) {
+			if bytes.EqualFold(area, []byte(name)) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"time"
+
+	"github.com/jcorbin/soc/internal/isotime"
+)
+
+const configFileName = ".soc.json"
+
+// Config contains user configuration, loaded from a configFileName file
+// alongside the stream.
+type Config struct {
+	// ItemTypes replaces the builtin item types (TODO, WIP, and Done) if
+	// non-empty.
+	ItemTypes []ItemTypeConfig `json:"itemTypes,omitempty"`
+
+	// DayFormat is a Go time layout used to write new day section headers,
+	// e.g. "2006-01-02 Monday"; it must start with an ISO date, and defaults
+	// to just that.
+	DayFormat string `json:"dayFormat,omitempty"`
+
+	// Aliases maps additional command names to existing commands, e.g.
+	// {"t": "today"}.
+	Aliases map[string]string `json:"aliases,omitempty"`
+
+	// DefaultCommand is run when the user gives no command, rather than
+	// printing help.
+	DefaultCommand string `json:"defaultCommand,omitempty"`
+
+	// TimeZone is the name of the time zone used to determine the present
+	// day, e.g. "America/New_York"; defaults to the system's local time zone.
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// DayStart is the time of day when the present day starts, e.g. "04:00"
+	// so that working past midnight still counts as the prior day; defaults
+	// to midnight.
+	DayStart string `json:"dayStart,omitempty"`
+}
+
+// loadConfig reads user config from the given store, returning its raw
+// content for change detection; returns zero Config if none exists.
+func loadConfig(st store) (cfg Config, raw []byte, _ error) {
+	rc, err := st.open()
+	if errors.Is(err, errStoreNotExists) {
+		return cfg, nil, nil
+	} else if err != nil {
+		return cfg, nil, err
+	}
+	defer rc.Close()
+	if raw, err = ioutil.ReadAll(rc); err != nil {
+		return cfg, nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return cfg, raw, fmt.Errorf("unable to decode %v: %w", configFileName, err)
+	}
+	return cfg, raw, nil
+}
+
+// checkDayFormat returns an error unless the given time layout starts with an
+// ISO date, so that headers written with it may be parsed back.
+func checkDayFormat(layout string) error {
+	sample := time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC).Format(layout)
+	if t, _, parsed := (isotime.GrainedTime{}).ParseString(sample); !parsed || t.Grain() != isotime.TimeGrainDay {
+		return fmt.Errorf("invalid day format %q, must start with an ISO date like 2006-01-02", layout)
+	}
+	return nil
+}
+
+// parseDayStart parses a time of day like 04:00 into a duration after
+// midnight.
+func parseDayStart(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid day start %q, must be a time of day like 04:00", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// setupConfig registers any configured command aliases and default command;
+// it must run after all builtin commands have been setup.
+func setupConfig(ctx *context) error {
+	names := make([]string, 0, len(ctx.config.Aliases))
+	for name := range ctx.config.Aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := ctx.mux.alias(name, ctx.config.Aliases[name]); err != nil {
+			return err
+		}
+	}
+	if name := ctx.config.DefaultCommand; name != "" {
+		if err := ctx.mux.alias("", name); err != nil {
+			return fmt.Errorf("invalid default command: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/jcorbin/soc/internal/isotime"
+	"github.com/jcorbin/soc/internal/scanio"
+	"github.com/jcorbin/soc/internal/socui"
+	"github.com/jcorbin/soc/scandown"
+)
+
+func init() {
+	builtinServer("defer", serveDefer,
+		"move an item from today onto a future day")
+}
+
+func serveDefer(ctx *context, req *socui.Request, res *socui.Response) error {
+	if err := ctx.today.collect(ctx.store, res); err != nil {
+		return err
+	}
+
+	usage := fmt.Sprintf("usage: %v <match...> <date>", ctx.Command())
+
+	// last arg is the date to defer until, all others must match exactly one
+	// item from today's first non-remnant section (e.g. TODO)
+	args := scanArgs(req)
+	if len(args) < 2 {
+		return errors.New(usage)
+	}
+	date, err := isotime.ParseDate(ctx.today.date.Time(), args[len(args)-1])
+	if err != nil {
+		return fmt.Errorf("%w, %v", err, usage)
+	}
+	if date.Grain() > isotime.TimeGrainDay {
+		return fmt.Errorf("cannot defer until %v, must be a year, month, week, or day", date)
+	}
+	if !date.Time().After(ctx.today.date.Time()) {
+		return fmt.Errorf("cannot defer until %v, must be after %v", date, ctx.today.date)
+	}
+
+	i := ctx.today.planSection()
+	if i < 0 {
+		return errors.New("no section to defer items from")
+	}
+	i += int(firstVarSection)
+	if i >= len(ctx.today.sections) || ctx.today.sections[i].id == 0 {
+		return fmt.Errorf("unable to find %v %q section", ctx.today.date, ctx.today.sectionNames[i-int(firstVarSection)])
+	}
+
+	var am argMatcher
+	if _, err := ctx.addMatchArgs(&am, args[:len(args)-1]); err != nil {
+		return err
+	}
+	cands, err := am.matchSection(&ctx.today, i, 0)
+	if err != nil {
+		return err
+	}
+	if len(cands) == 0 || len(cands[0].rem) > 0 {
+		return fmt.Errorf("no %v item matched %q", ctx.today.titles[i], am.args)
+	}
+	if len(cands) > 1 || ctx.chosen != nil {
+		j, err := ctx.choose(req, res, fmt.Sprintf(
+			"ambiguous match for %q, choose one of %v candidate items",
+			am.args, len(cands),
+		), cands.options())
+		if err != nil || j < 0 {
+			return err
+		}
+		cands = cands[j : j+1]
+	}
+	sec, match := ctx.today.sections[i], cands[0].match
+
+	// render the deferred item before it moves
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# Deferred from %v until %v\n", ctx.today.titles[i], date)
+	ctx.today.sc.Reset(sec.body())
+	if err := ctx.today.sc.printOutline(&buf, match.filter()); err != nil {
+		return err
+	}
+
+	if err := ctx.today.deferItem(ctx.store, date, match); err != nil {
+		return err
+	}
+
+	res.Break()
+	_, err = buf.WriteTo(res)
+	return err
+}
+
+// deferItem moves the last matched item, under an atomic stream edit, onto
+// the planned section for the given date. If no such section exists, a new one
+// is created just before the first older dated section, keeping the stream in
+// reverse chronological order.
+func (pres *presentDay) deferItem(st store, date isotime.GrainedTime, from *outlineMatch) error {
+	sec, at, err := pres.findPlannedDay(date)
+	if err != nil {
+		return err
+	}
+	if sec.id != 0 {
+		return pres.moveItem(st, sec, from)
+	}
+
+	path, err := from.pathTitles(len(from.within) - 1)
+	if err != nil {
+		return err
+	}
+	title := date.String()
+	if date.Grain() == isotime.TimeGrainDay {
+		title = pres.dayTitle(date)
+	}
+	place := itemPlace{at: at, eol: true, delim: '-'}
+	return pres.moveItemTo(st, place, fmt.Sprintf("# %v\n\n", title), path[:len(path)-1], from)
+}
+
+// findPlannedDay scans the stream for a toplevel section heading with the
+// given date. If no such section exists, it returns an empty token at the start
+// of the first older dated section instead.
+func (pres *presentDay) findPlannedDay(date isotime.GrainedTime) (found section, at scanio.Token, _ error) {
+	var sc outlineScanner
+	sc.loc = pres.date.Location()
+	for sc.Reset(pres.FileArena); sc.Scan(); {
+		if found.id != 0 {
+			if found = sc.updateSection(found); !found.scanning {
+				return found, at, nil
+			}
+			continue
+		}
+
+		// only consider toplevel headings that contain only a date
+		if !sc.titled || len(sc.outline.id) != 1 || sc.outline.block[0].Type != scandown.Heading {
+			continue
+		}
+		t := sc.lastTime()
+		if t.Grain() == 0 {
+			continue
+		}
+		if title := sc.title[0]; !title.Empty() {
+			if b, _ := title.Bytes(); !pres.isDayRemnant(t, b) {
+				continue
+			}
+		}
+
+		if t.Equal(date) {
+			found = sc.openSection()
+		} else if tt, dt := t.Time(), date.Time(); tt.Before(dt) || tt.Equal(dt) && t.Grain() < date.Grain() {
+			off := int(sc.block.Offset())
+			return found, sc.arena.Ref(off, off), nil
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return found, at, err
+	}
+	if found.id != 0 {
+		return sc.updateSection(found), at, nil
+	}
+	return found, at, fmt.Errorf("unable to find a place for %v in stream", date)
+}
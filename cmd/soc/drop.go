@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jcorbin/soc/internal/socui"
+)
+
+func init() {
+	builtinServer("drop", serveDrop,
+		"remove an item (and its children) from today")
+}
+
+func serveDrop(ctx *context, req *socui.Request, res *socui.Response) error {
+	if err := ctx.today.collect(ctx.store, res); err != nil {
+		return err
+	}
+
+	usage := fmt.Sprintf("usage: %v <%v> <match...>",
+		ctx.Command(), strings.ToLower(strings.Join(ctx.today.sectionNames, "|")))
+
+	// first arg names the today sub-section to drop from
+	if !req.ScanArg() {
+		return errors.New(usage)
+	}
+	name := req.Arg()
+	i := ctx.today.matchSectionString(name)
+	if i < 0 {
+		return fmt.Errorf("unknown section %q, %v", name, usage)
+	}
+	i += int(firstVarSection)
+	if i >= len(ctx.today.sections) || ctx.today.sections[i].id == 0 {
+		return fmt.Errorf("unable to find %v %q section", ctx.today.date, name)
+	}
+
+	// remaining args must match exactly one item
+	var am argMatcher
+	if _, err := ctx.addMatchArgs(&am, scanArgs(req)); err != nil {
+		return err
+	} else if len(am.args) == 0 {
+		return errors.New(usage)
+	}
+	cands, err := am.matchSection(&ctx.today, i, 0)
+	if err != nil {
+		return err
+	}
+	if len(cands) == 0 || len(cands[0].rem) > 0 {
+		return fmt.Errorf("no %v item matched %q", ctx.today.titles[i], am.args)
+	}
+	if len(cands) > 1 || ctx.chosen != nil {
+		j, err := ctx.choose(req, res, fmt.Sprintf(
+			"ambiguous match for %q, choose one of %v candidate items",
+			am.args, len(cands),
+		), cands.options())
+		if err != nil || j < 0 {
+			return err
+		}
+		cands = cands[j : j+1]
+	}
+	sec, match := ctx.today.sections[i], cands[0].match
+
+	// render the dropped item before it's gone
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# Dropped from %v\n", ctx.today.titles[i])
+	ctx.today.sc.Reset(sec.body())
+	if err := ctx.today.sc.printOutline(&buf, match.filter()); err != nil {
+		return err
+	}
+
+	if err := ctx.today.dropItem(ctx.store, match); err != nil {
+		return err
+	}
+
+	res.Break()
+	_, err = buf.WriteTo(res)
+	return err
+}
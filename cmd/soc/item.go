@@ -0,0 +1,404 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/jcorbin/soc/internal/scanio"
+	"github.com/jcorbin/soc/scandown"
+)
+
+// itemPlace is a location within the stream where new list items may be
+// written: just after the last non-blank line of some section or item.
+type itemPlace struct {
+	at     scanio.Token // content to insert after
+	eol    bool         // true if at ends with a newline
+	indent int          // indent width for new items
+	delim  byte         // list item delimiter for new items
+}
+
+// findItemPlace returns a place to add new child items under the last matched
+// item, or at the end of the section body if nothing was matched.
+func findItemPlace(sec section, under *outlineMatch) (place itemPlace, err error) {
+	into := sec.body()
+	place.delim = '-'
+	if !under.empty() {
+		i := len(under.within) - 1
+		into = under.within[i].Token
+		place.indent = under.childIndent(i)
+		if b := under.block[i]; b.Type == scandown.Item {
+			switch b.Delim {
+			case '-', '*', '+':
+				place.delim = b.Delim
+			}
+		}
+	}
+
+	place.at, place.eol, err = trimBlankLines(into)
+	if err == nil && place.at.Empty() && under.empty() {
+		// empty section body, add just after its header
+		_, place.eol, err = trimBlankLines(sec.header())
+	}
+	return place, err
+}
+
+// writeItem writes a new list item line with the given text, returning a
+// place to write any children of the new item.
+func (place itemPlace) writeItem(w io.Writer, text string) (itemPlace, error) {
+	if !place.eol {
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return place, err
+		}
+		place.eol = true
+	}
+	_, err := fmt.Fprintf(w, "%s%c %s\n", strings.Repeat(" ", place.indent), place.delim, text)
+	place.indent += 2
+	return place, err
+}
+
+// addItem adds a new list item with the given text, under an atomic stream
+// edit. The item is added as the last child of any matched item, otherwise as
+// the last item within the given section's body. Any path titles are first
+// added as intermediate parent items.
+func (pres *presentDay) addItem(st store, sec section, under *outlineMatch, path []string, text string) error {
+	place, err := findItemPlace(sec, under)
+	if err != nil {
+		return err
+	}
+	return pres.edit(st, func(ed *scanio.Editor) (err error) {
+		cur := ed.CursorAt(place.at.End())
+		for _, title := range path {
+			if place, err = place.writeItem(cur, title); err != nil {
+				return err
+			}
+		}
+		_, err = place.writeItem(cur, text)
+		return err
+	})
+}
+
+// moveItem moves the last item matched within a sibling section, along with
+// all of its content and children, into the given section. The matched
+// item's parent path is reused if it already exists within sec, otherwise any
+// missing parents are created.
+func (pres *presentDay) moveItem(st store, sec section, from *outlineMatch) error {
+	path, err := from.pathTitles(len(from.within) - 1)
+	if err != nil {
+		return err
+	}
+	under, path, err := pres.matchPath(sec, path[:len(path)-1])
+	if err != nil {
+		return err
+	}
+	place, err := findItemPlace(sec, under)
+	if err != nil {
+		return err
+	}
+	return pres.moveItemTo(st, place, "", path, from)
+}
+
+// moveItemTo moves the last matched item, along with all of its content and
+// children, to the given place. Any header is first written as-is, followed
+// by any path titles as intermediate parent items; a blank line is written
+// after the moved item if header is non-empty. Any parent [area] items left
+// empty at the source are pruned, as is any blank line that would be left
+// doubled; other parents, like a task whose last sub-item moved, remain.
+func (pres *presentDay) moveItemTo(st store, place itemPlace, header string, path []string, from *outlineMatch) error {
+	i := len(from.within) - 1
+	b := from.block[i]
+	if b.Type != scandown.Item {
+		return fmt.Errorf("cannot move %v, only list items may be moved", b)
+	}
+	item, eol, err := trimBlankLines(from.within[i].Token)
+	if err != nil {
+		return err
+	}
+	extent, err := from.itemExtent(i, true)
+	if err == nil {
+		extent, err = collapseBlankLines(pres.FileArena, extent)
+	}
+	if err != nil {
+		return err
+	}
+
+	return pres.edit(st, func(ed *scanio.Editor) (err error) {
+		ed.Remove(extent)
+		loc := place.at.End()
+		if loc >= extent.End() {
+			loc -= extent.Len()
+		} else if loc > extent.Start() {
+			loc = extent.Start()
+		}
+		cur := ed.CursorAt(loc)
+
+		if header != "" {
+			cur.WriteString(header)
+		}
+		for _, title := range path {
+			if place, err = place.writeItem(cur, title); err != nil {
+				return err
+			}
+		}
+		if !place.eol {
+			cur.WriteString("\n")
+		}
+
+		// item may need to be re-indented to fit under its new parent
+		if delta := place.indent - (from.childIndent(i) - b.Width); delta == 0 {
+			cur.Insert(item)
+		} else if err := reindentInto(cur, item, delta); err != nil {
+			return err
+		}
+		if !eol {
+			cur.WriteString("\n")
+		}
+		if header != "" {
+			cur.WriteString("\n")
+		}
+		return nil
+	})
+}
+
+// dropItem removes the last matched item, along with all of its content and
+// children, under an atomic stream edit. Any parent items left empty are
+// pruned, as is any blank line that would be left doubled.
+func (pres *presentDay) dropItem(st store, match *outlineMatch) error {
+	extent, err := match.itemExtent(len(match.within)-1, false)
+	if err == nil {
+		extent, err = collapseBlankLines(pres.FileArena, extent)
+	}
+	if err != nil {
+		return err
+	}
+	return pres.edit(st, func(ed *scanio.Editor) error {
+		ed.Remove(extent)
+		return nil
+	})
+}
+
+// itemExtent returns a token spanning the i-th matched item and all of its
+// content, extended to cover any parent items that contain nothing else;
+// i.e. removing the returned token prunes any parents that would be left empty.
+// If areasOnly, only parents that just reference an [area] are pruned.
+func (om *outlineMatch) itemExtent(i int, areasOnly bool) (scanio.Token, error) {
+	extent, _, err := trimBlankLines(om.within[i].Token)
+	if err != nil {
+		return extent, err
+	}
+
+	// collect parent item ids, nearest first
+	var ids []int
+	for j := i - 1; j >= 0 && om.group[j] == om.group[i]; j-- {
+		if b := om.block[j]; b.Type == scandown.Item {
+			if areasOnly && !om.isArea(j) {
+				break
+			}
+			ids = append(ids, om.matched[j])
+		} else if b.Type != scandown.List {
+			break
+		}
+	}
+	if len(ids) == 0 {
+		return extent, nil
+	}
+
+	parents, err := scanSections(om.arena, ids...)
+	if err != nil {
+		return extent, err
+	}
+	for _, parent := range parents {
+		if parent.id == 0 {
+			break
+		}
+		body := parent.body()
+		if extent.Start() < body.Start() || extent.End() > body.End() {
+			break
+		}
+		pre, _, err := trimBlankLines(body.Slice(0, extent.Start()-body.Start()))
+		if err != nil {
+			return extent, err
+		}
+		post, _, err := trimBlankLines(body.Slice(extent.End()-body.Start(), -1))
+		if err != nil {
+			return extent, err
+		}
+		if !pre.Empty() || !post.Empty() {
+			break
+		}
+		if extent, _, err = trimBlankLines(parent.Token); err != nil {
+			return extent, err
+		}
+	}
+	return extent, nil
+}
+
+// isArea returns true if the j-th matched title is just an [area] reference,
+// like "[scanio]", serving only to group the items under it.
+func (om *outlineMatch) isArea(j int) bool {
+	out := outline{title: om.title[j : j+1]}
+	areas := out.areas(nil, 0)
+	b, _ := om.title[j].Bytes()
+	return len(areas) == 1 && string(bytes.TrimSpace(b)) == "["+string(areas[0])+"]"
+}
+
+// collapseBlankLines extends a line-aligned extent within arena back over any
+// blank lines just before it, if it's followed by a blank line or the end of
+// the arena; i.e. removing the returned token won't leave a doubled blank line
+// behind.
+func collapseBlankLines(ar scanio.Arena, extent scanio.Token) (scanio.Token, error) {
+	var buf [256]byte
+
+	n, err := ar.ReadAt(buf[:], int64(extent.End()))
+	if err != nil && err != io.EOF {
+		return extent, err
+	}
+	after := buf[:n]
+	if i := bytes.IndexByte(after, '\n'); i >= 0 {
+		after = after[:i]
+	} else if n == len(buf) {
+		return extent, nil
+	}
+	if len(bytes.TrimSpace(after)) > 0 {
+		return extent, nil
+	}
+
+	start := extent.Start() - len(buf)
+	if start < 0 {
+		start = 0
+	}
+	before := buf[:extent.Start()-start]
+	if _, err := ar.ReadAt(before, int64(start)); err != nil && err != io.EOF {
+		return extent, err
+	}
+	cut := len(before)
+	for cut > 0 {
+		i := bytes.LastIndexByte(before[:cut-1], '\n')
+		if i < 0 && start > 0 {
+			break // line may continue before buf
+		}
+		if len(bytes.TrimSpace(before[i+1:cut])) > 0 {
+			break
+		}
+		cut = i + 1
+	}
+	if cut == len(before) {
+		return extent, nil
+	}
+	return ar.Ref(start+cut, extent.End()), nil
+}
+
+// scanSections scans the given arena for the sections of the given Heading
+// or Item block ids, returning them in the same order; the zero section is
+// returned for any id not found.
+func scanSections(arena scanio.Arena, ids ...int) ([]section, error) {
+	var sc outlineScanner
+	secs := make([]section, len(ids))
+	for sc.Reset(arena); sc.Scan(); {
+		for i, sec := range secs {
+			secs[i] = sc.updateSection(sec)
+		}
+		if !sc.titled {
+			continue
+		}
+		sec := sc.openSection()
+		for i, id := range ids {
+			if id == sec.id && secs[i].id == 0 {
+				secs[i] = sec
+			}
+		}
+	}
+	for i, sec := range secs {
+		secs[i] = sc.updateSection(sec)
+	}
+	return secs, sc.Err()
+}
+
+// matchPath matches as much of the given title path as possible within a
+// section, returning the match and any remaining unmatched titles.
+func (pres *presentDay) matchPath(sec section, path []string) (*outlineMatch, []string, error) {
+	if len(path) == 0 {
+		return nil, nil, nil
+	}
+	patterns := make([]*regexp.Regexp, len(path))
+	for i, title := range path {
+		pattern, err := compileTitlePattern(title)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to compile regexp for path[%v]:%q : %w", i, title, err)
+		}
+		patterns[i] = pattern
+	}
+	var (
+		sc    outlineScanner
+		match outlineMatch
+	)
+	if err := sc.matchOutline(&match, sec.body(), patterns...); err != nil {
+		return nil, nil, err
+	}
+	match.truncateGroups(1)
+	return &match, path[match.maxNextArg():], nil
+}
+
+// reindentInto writes all lines from tok into w, adding delta spaces to the
+// start of each non-blank line, or removing up to -delta spaces if negative.
+func reindentInto(w io.Writer, tok scanio.Token, delta int) error {
+	b, err := tok.Bytes()
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	buf.Grow(len(b) + len(b)/4)
+	for len(b) > 0 {
+		line := b
+		if i := bytes.IndexByte(line, '\n'); i >= 0 {
+			line = line[:i+1]
+		}
+		b = b[len(line):]
+		if len(bytes.TrimSpace(line)) > 0 {
+			if delta > 0 {
+				buf.WriteString(strings.Repeat(" ", delta))
+			}
+			for j := 0; j < -delta && len(line) > 0 && line[0] == ' '; j++ {
+				line = line[1:]
+			}
+		}
+		buf.Write(line)
+	}
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+// trimBlankLines returns the prefix of tok before any trailing blank lines,
+// and whether that prefix ends with a final newline. If tok contains only
+// blank lines, an empty prefix is returned with eol true.
+func trimBlankLines(tok scanio.Token) (_ scanio.Token, eol bool, _ error) {
+	if tok.Empty() {
+		return tok, true, nil
+	}
+
+	var buf [256]byte
+	cut, eol := tok.Len(), false
+	for end := tok.Len(); end > 0; {
+		start := end - len(buf)
+		if start < 0 {
+			start = 0
+		}
+		b := buf[:end-start]
+		if _, err := tok.ReadAt(b, int64(start)); err != nil && err != io.EOF {
+			return tok, false, err
+		}
+		for i := len(b) - 1; i >= 0; i-- {
+			switch b[i] {
+			case '\n':
+				cut, eol = start+i+1, true
+			case ' ', '\t', '\r':
+			default:
+				return tok.Slice(0, cut), eol, nil
+			}
+		}
+		end = start
+	}
+	return tok.Slice(0, 0), true, nil
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"errors"
+	"flag"
 	"fmt"
 
 	"github.com/jcorbin/soc/internal/isotime"
@@ -10,10 +11,20 @@ import (
 
 func init() {
 	builtinServer("list", serveList,
-		"print stream outline listing")
+		"print stream outline listing, optionally filtered by a query")
 }
 
-func serveList(ctx *context, _ *socui.Request, res *socui.Response) (rerr error) {
+func serveList(ctx *context, req *socui.Request, res *socui.Response) (rerr error) {
+	flags := flag.NewFlagSet(ctx.Command(), flag.ContinueOnError)
+	flags.SetOutput(res)
+	raw := flags.Bool("raw", false, "print raw markdown sections, rather than an outline")
+	terms, err := parseFlags(flags, scanArgs(req))
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
 	if err := ctx.today.open(ctx.store); errors.Is(err, errStoreNotExists) {
 		fmt.Fprintf(res, "stream is empty, run `%v today` to initialize\n", ctx.args[0])
 		fmt.Fprintf(res, "... or just start adding items with `%v <todo|wip|done> ...`\n", ctx.args[0])
@@ -21,5 +32,16 @@ func serveList(ctx *context, _ *socui.Request, res *socui.Response) (rerr error)
 	} else if err != nil {
 		return err
 	}
-	return ctx.today.sc.printOutline(res, mustCompileOutlineFilter(isotime.TimeGrainYear, 1))
+	filter := mustCompileOutlineFilter(isotime.TimeGrainYear, 1)
+	if len(terms) > 0 {
+		query, err := ctx.today.compileQuery(terms)
+		if err != nil {
+			return fmt.Errorf("%w, usage: %v [-raw] [%v]", err, ctx.Command(), queryHelp)
+		}
+		filter = query
+	}
+	if *raw {
+		return ctx.today.sc.printRaw(res, filter)
+	}
+	return ctx.today.sc.printOutline(res, filter)
 }
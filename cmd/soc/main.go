@@ -1,11 +1,14 @@
 package main
 
 import (
+	"flag"
 	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"time"
 
+	"github.com/jcorbin/soc/internal/lineedit"
 	"github.com/jcorbin/soc/internal/socui"
 	"github.com/jcorbin/soc/internal/socutil"
 )
@@ -34,10 +37,21 @@ func main() {
 		ui.store = &fst
 	}
 
-	// run the user command(s)
-	// TODO option for a simple REPL at least
-	if err := socui.CLIRequest().Serve(os.Stdout, &ui); err != nil {
-		log.Fatalln(err)
+	// run the user command(s), and then any interactive loop
+	interactive := flag.Bool("i", false, "run commands interactively, after any given as args")
+	flag.StringVar(&ui.asOf, "date", "", "run commands as of the given date, like 2020-09-03 or yesterday, rather than today; changes as of any future date are only previewed, not saved")
+	flag.Parse()
+	if flag.NArg() > 0 || !*interactive {
+		if err := socui.CLIRequest().Serve(os.Stdout, &ui); err != nil {
+			log.Fatalln(err)
+		}
+	}
+	if *interactive || ui.interactive {
+		ed := lineedit.New(os.Stdin, os.Stdout)
+		ed.Prompt = ui.args[0] + "> "
+		if err := ui.repl(ed, os.Stdout, time.Now); err != nil {
+			log.Fatalln(err)
+		}
 	}
 }
 
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jcorbin/soc/internal/socui"
+)
+
+func init() {
+	builtinServer("note", serveNote,
+		"add a note under an item from today")
+}
+
+func serveNote(ctx *context, req *socui.Request, res *socui.Response) error {
+	if err := ctx.today.collect(ctx.store, res); err != nil {
+		return err
+	}
+
+	usage := fmt.Sprintf("usage: %v <match...> <note>", ctx.Command())
+
+	// last arg is the note, all others must match exactly one item
+	args := scanArgs(req)
+	if len(args) < 2 {
+		return errors.New(usage)
+	}
+	text := args[len(args)-1]
+	var am argMatcher
+	lastBefore, err := ctx.addMatchArgs(&am, args[:len(args)-1])
+	if err != nil {
+		return err
+	}
+	cands, err := am.matchPresent(&ctx.today, -1, lastBefore)
+	if err != nil {
+		return err
+	}
+	if len(cands) == 0 || len(cands[0].rem) > 0 {
+		return fmt.Errorf("no item matched %q", am.args)
+	}
+	if tied := cands.tied(); len(tied) > 1 || ctx.chosen != nil {
+		i, err := ctx.choose(req, res, fmt.Sprintf(
+			"ambiguous match for %q, choose one of %v candidate items",
+			am.args, len(tied),
+		), tied.options())
+		if err != nil || i < 0 {
+			return err
+		}
+		cands = tied[i : i+1]
+	}
+	noted := cands[0]
+
+	// add the note, then re-match it against the reloaded stream
+	i := noted.section
+	if err := ctx.today.addItem(ctx.store, ctx.today.sections[i], noted.match, nil, text); err != nil {
+		return err
+	}
+	if err := ctx.touchItem(noted.match); err != nil {
+		return err
+	}
+	am.truncate(0)
+	if err := am.addPath(append(noted.path, text)...); err != nil {
+		return err
+	}
+	sec := ctx.today.sections[i]
+	match, _, err := am.match(sec)
+	if err != nil {
+		return err
+	}
+
+	res.Break()
+	fmt.Fprintf(res, "# %v\n", ctx.today.titles[i])
+	ctx.today.sc.Reset(sec.body())
+	return ctx.today.sc.printOutline(res, match.filter())
+}
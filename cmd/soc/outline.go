@@ -54,6 +54,7 @@ type outline struct {
 	time   []isotime.GrainedTime // time parsed from block title prefixes
 	title  []scanio.Token        // title remnants (after time)
 	arena  scanio.ByteArena      // title storage
+	loc    *time.Location        // location of parsed times, time.Local if nil
 }
 
 // outlineScanner orchestrates a low level scanner, block stack, and outline.
@@ -189,7 +190,7 @@ func (out *outline) sync(blocks scandown.BlockStack) {
 			}
 		}
 
-		t := isotime.Time(time.Local, 0, 0, 0, 0, 0, 0)
+		t := isotime.Time(out.loc, 0, 0, 0, 0, 0, 0)
 		if j := i - 1; j >= 0 {
 			t = out.time[j]
 		}
@@ -217,7 +218,7 @@ func (out *outline) sync(blocks scandown.BlockStack) {
 		switch b, _ := blocks.Block(j); b.Type {
 		// track list structure...
 		case scandown.List, scandown.Item:
-			t := isotime.Time(time.Local, 0, 0, 0, 0, 0, 0)
+			t := isotime.Time(out.loc, 0, 0, 0, 0, 0, 0)
 			if j := i - 1; j >= 0 {
 				t = out.time[j]
 			}
@@ -264,10 +265,15 @@ func (out *outline) readTitle(t isotime.GrainedTime, r io.Reader) (isotime.Grain
 	title := out.arena.Take()
 	tb, _ := title.Bytes()
 
-	// parse any date components from the title prefix
+	// parse any date components from the title prefix; the title may restate
+	// an absolute time within its parent's, e.g. a day within a week
 	{
-		if st, rb, parsed := t.Parse(tb); parsed {
-			parsedLen := len(tb) - len(rb)
+		if st, rb, parsed := isotime.Time(out.loc, 0, 0, 0, 0, 0, 0).Parse(tb); parsed && t.Grain() > 0 && t.Contains(st) {
+			parsedLen := len(tb) - len(bytes.TrimLeft(rb, " "))
+			title = title.Slice(parsedLen, -1)
+			t = st
+		} else if st, rb, parsed := t.Parse(tb); parsed {
+			parsedLen := len(tb) - len(bytes.TrimLeft(rb, " "))
 			title = title.Slice(parsedLen, -1)
 			t = st
 		}
@@ -460,6 +466,8 @@ func outlineFilters(filters ...outlineFilter) outlineFilter {
 type outlineFilter interface{ match(out *outline) bool }
 type outlineFilterConst bool
 type outlineFilterAnd []outlineFilter
+type outlineFilterOr []outlineFilter
+type outlineFilterNot struct{ outlineFilter }
 type outlineFilterFunc func(out *outline) bool
 
 func (c outlineFilterConst) match(out *outline) bool { return bool(c) }
@@ -472,6 +480,15 @@ func (fs outlineFilterAnd) match(out *outline) bool {
 	}
 	return true
 }
+func (fs outlineFilterOr) match(out *outline) bool {
+	for _, f := range fs {
+		if f.match(out) {
+			return true
+		}
+	}
+	return false
+}
+func (n outlineFilterNot) match(out *outline) bool { return !n.outlineFilter.match(out) }
 
 type outlineTimeGrainFilter isotime.TimeGrain
 
@@ -495,6 +512,42 @@ func isOneOfType(t scandown.BlockType, oneOf ...scandown.BlockType) bool {
 	return false
 }
 
+// printRaw copies the markdown of each toplevel section that contains any
+// outline item matching filters, as it's scanned.
+func (sc *outlineScanner) printRaw(to io.Writer, filters ...outlineFilter) error {
+	filter := outlineFilters(filters...)
+	var (
+		sec     section
+		matched bool
+	)
+	flush := func() (err error) {
+		if sec.id != 0 && !sec.scanning {
+			if matched {
+				_, err = scanio.CopyTokens(to, sec.Token)
+			}
+			sec, matched = section{}, false
+		}
+		return err
+	}
+	for sc.Scan() {
+		sec = sc.updateSection(sec)
+		if err := flush(); err != nil {
+			return err
+		}
+		if !sc.titled {
+			continue
+		}
+		if sec.id == 0 {
+			sec = sc.openSection()
+		}
+		if filter == nil || filter.match(&sc.outline) {
+			matched = true
+		}
+	}
+	sec = sc.updateSection(sec)
+	return flush()
+}
+
 func (sc *outlineScanner) printOutline(to io.Writer, filters ...outlineFilter) error {
 	filter := outlineFilters(filters...)
 	var (
@@ -571,6 +624,9 @@ func (sc *outlineScanner) printOutline(to io.Writer, filters ...outlineFilter) e
 				}
 				buf.WriteByte(' ')
 				fmt.Fprint(&buf, nt)
+				if title.Len() > 0 {
+					buf.WriteByte(' ')
+				}
 			} else if title.Len() == 0 {
 				continue
 			} else {
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jcorbin/soc/internal/isotime"
+	"github.com/jcorbin/soc/scandown"
+)
+
+// queryHelp describes the query syntax understood by compileQuery.
+const queryHelp = `terms like since:2020-09 until:2020-09-07 level:2 section:done text:/regexp/ tag:name #name or plain words, combined with OR and NOT`
+
+// compileQuery compiles a list of query terms into an outline filter. Terms
+// match every outline item by default, and are combined with:
+//
+//	a b        both a and b must match
+//	a OR b     either a or b must match; binds looser than and
+//	NOT a, -a  a must not match
+//
+// Each term is either a plain word, matched case-insensitively against item
+// titles, or one of the following keyed terms:
+//
+//	since:DATE      items dated within or after DATE
+//	until:DATE      items dated within or before DATE
+//	level:N         items at outline level N, e.g. 1 for toplevel items
+//	section:NAME    items within an item section, e.g. done
+//	text:WORD       item titles containing WORD, or matching /regexp/
+//	tag:NAME, #NAME item titles carrying the #NAME tag
+//
+// Any DATE may be relative to the present day, e.g. since:monday.
+func (pres *presentDay) compileQuery(args []string) (outlineFilter, error) {
+	var (
+		or  outlineFilterOr
+		and outlineFilterAnd
+		not bool
+	)
+	endAnd := func() error {
+		if not {
+			return errors.New("NOT must be followed by a term")
+		}
+		switch len(and) {
+		case 0:
+			return errors.New("OR must be between terms")
+		case 1:
+			or = append(or, and[0])
+		default:
+			or = append(or, and)
+		}
+		and = nil
+		return nil
+	}
+	for _, arg := range args {
+		switch arg {
+		case "OR":
+			if err := endAnd(); err != nil {
+				return nil, err
+			}
+			continue
+		case "NOT":
+			not = !not
+			continue
+		}
+		if len(arg) > 1 && arg[0] == '-' {
+			not, arg = !not, arg[1:]
+		}
+		f, err := pres.compileQueryTerm(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid query term %q: %w", arg, err)
+		}
+		if not {
+			f, not = outlineFilterNot{f}, false
+		}
+		and = append(and, f)
+	}
+	if len(and) == 0 && len(or) == 0 && !not {
+		return nil, nil
+	}
+	if err := endAnd(); err != nil {
+		return nil, err
+	}
+	if len(or) == 1 {
+		return or[0], nil
+	}
+	return or, nil
+}
+
+func (pres *presentDay) compileQueryTerm(term string) (outlineFilter, error) {
+	if len(term) > 1 && term[0] == '#' {
+		return outlineTagFilter(term[1:]), nil
+	}
+	i := strings.IndexByte(term, ':')
+	if i < 0 {
+		pattern, err := compileArgPattern(term)
+		if err != nil {
+			return nil, err
+		}
+		return outlineTitleFilter{pattern}, nil
+	}
+	key, val := term[:i], term[i+1:]
+	if val == "" {
+		return nil, fmt.Errorf("missing %v value", key)
+	}
+	switch key {
+	case "since", "until":
+		t, err := isotime.ParseDate(pres.date.Time(), val)
+		if err != nil {
+			return nil, err
+		}
+		if key == "since" {
+			return outlineSinceFilter{t}, nil
+		}
+		return outlineUntilFilter{t}, nil
+
+	case "level":
+		n, err := strconv.Atoi(val)
+		if err != nil || n < 1 {
+			return nil, errors.New("level must be a positive number")
+		}
+		return outlineLevelFilter(n), nil
+
+	case "section":
+		i := pres.matchSectionString(val)
+		if i < 0 {
+			return nil, fmt.Errorf("unknown section, expected one of %v",
+				strings.ToLower(strings.Join(pres.sectionNames, ", ")))
+		}
+		return pres.sectionFilter(i), nil
+
+	case "text":
+		if len(val) > 1 && val[0] == '/' && val[len(val)-1] == '/' {
+			pattern, err := regexp.Compile(val[1 : len(val)-1])
+			if err != nil {
+				return nil, err
+			}
+			return outlineTitleFilter{pattern}, nil
+		}
+		pattern, err := compileArgPattern(val)
+		if err != nil {
+			return nil, err
+		}
+		return outlineTitleFilter{pattern}, nil
+
+	case "tag":
+		return outlineTagFilter(strings.TrimPrefix(val, "#")), nil
+
+	default:
+		return nil, fmt.Errorf("unknown key %q, expected %v", key, queryHelp)
+	}
+}
+
+// outlineTitleFilter matches outline items whose own title matches a pattern.
+type outlineTitleFilter struct{ pattern *regexp.Regexp }
+
+func (f outlineTitleFilter) match(out *outline) bool {
+	if i := len(out.title) - 1; i >= 0 {
+		b, _ := out.title[i].Bytes()
+		return f.pattern.Match(b)
+	}
+	return false
+}
+
+// outlineSinceFilter matches outline items dated within or after a time.
+type outlineSinceFilter struct{ t isotime.GrainedTime }
+
+func (f outlineSinceFilter) match(out *outline) bool {
+	t := out.lastTime()
+	return t.Grain() > 0 && !t.Start().Before(f.t.Start())
+}
+
+// outlineUntilFilter matches outline items dated within or before a time.
+type outlineUntilFilter struct{ t isotime.GrainedTime }
+
+func (f outlineUntilFilter) match(out *outline) bool {
+	t := out.lastTime()
+	return t.Grain() > 0 && t.Start().Before(f.t.End())
+}
+
+// outlineSectionFilter matches outline items within an item section heading,
+// e.g. under "## Done". Since rolling a day forward elides the header of the
+// first remnant section left behind, it also matches any item directly within
+// a prior day for that section.
+type outlineSectionFilter struct {
+	pc     presentConfig
+	index  int
+	before isotime.GrainedTime // zero unless the section header may be elided
+}
+
+// sectionFilter returns a filter matching items within the i-th today
+// sub-section.
+func (pres *presentDay) sectionFilter(i int) outlineSectionFilter {
+	f := outlineSectionFilter{pc: pres.presentConfig, index: i}
+	if i == pres.remnantSection() {
+		f.before = pres.date
+	}
+	return f
+}
+
+func (f outlineSectionFilter) match(out *outline) bool {
+	for i, title := range out.title {
+		if out.block[i].Type != scandown.Heading {
+			continue
+		}
+		if b, _ := title.Bytes(); len(b) > 0 {
+			if j := f.pc.matchSection(b); j >= 0 {
+				return j == f.index
+			}
+		}
+	}
+	return f.before.Grain() > 0 && len(out.time) > 1 &&
+		out.block[0].Type == scandown.Heading &&
+		out.time[0].Grain() == isotime.TimeGrainDay && out.time[0].Before(f.before)
+}
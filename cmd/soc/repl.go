@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jcorbin/soc/internal/lineedit"
+	"github.com/jcorbin/soc/internal/socui"
+)
+
+func init() {
+	builtinServer("repl", serveREPL,
+		"run further commands interactively, one per line")
+}
+
+func serveREPL(ctx *context, req *socui.Request, res *socui.Response) error {
+	if ctx.interactive {
+		fmt.Fprintf(res, "already running interactively\n")
+		return nil
+	}
+	ctx.interactive = true
+	fmt.Fprintf(res, "Enter commands one per line, Ctrl-D to exit.\n")
+	return nil
+}
+
+// repl serves each line read from ed as a request, writing responses to out,
+// until ed returns io.EOF. Errors from each request are reported to the user,
+// rather than ending the loop.
+func (ui *ui) repl(ed *lineedit.Editor, out io.Writer, now func() time.Time) error {
+	ui.interactive = true
+	defer ui.Close()
+	ed.Complete = ui.complete
+	for {
+		line, err := ed.ReadLine()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if err := socui.StreamRequest(now(), strings.NewReader(line)).Serve(out, ui); err != nil {
+			fmt.Fprintf(out, "error: %v\n", err)
+		}
+	}
+}
+
+// complete returns completions for the last word in head: command names for
+// the first word, or today's item titles for any later word.
+func (ui *ui) complete(head string) (start int, words []string) {
+	quoted := false
+	for i, r := range head {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ' ' && !quoted {
+			start = i + 1
+		}
+	}
+	prefix := strings.ToLower(strings.TrimPrefix(head[start:], `"`))
+
+	var cands []string
+	if strings.TrimSpace(head[:start]) == "" {
+		for name := range ui.mux {
+			if name != "" && !strings.HasPrefix(name, ".") {
+				cands = append(cands, name)
+			}
+		}
+	} else {
+		cands = ui.today.itemTitles()
+	}
+
+	for _, cand := range cands {
+		if strings.HasPrefix(strings.ToLower(cand), prefix) {
+			words = append(words, string(socui.QuotedArgs([]string{cand})))
+		}
+	}
+	sort.Strings(words)
+	return start, words
+}
+
+// itemTitles returns all distinct outline item titles within today.
+func (pres *presentDay) itemTitles() (titles []string) {
+	sec := pres.sections[todaySection]
+	if sec.id == 0 {
+		return nil
+	}
+	var sc outlineScanner
+	seen := make(map[string]bool)
+	for sc.Reset(sec.body()); sc.Scan(); {
+		if !sc.titled {
+			continue
+		}
+		if title, _ := sc.title[len(sc.title)-1].Text(); title != "" && !seen[title] {
+			seen[title] = true
+			titles = append(titles, title)
+		}
+	}
+	return titles
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jcorbin/soc/internal/isotime"
+	"github.com/jcorbin/soc/internal/socui"
+	"github.com/jcorbin/soc/scandown"
+)
+
+func init() {
+	builtinServer("report", serveReport,
+		"print a markdown digest of Done items from a range of days")
+}
+
+func serveReport(ctx *context, req *socui.Request, res *socui.Response) error {
+	usage := fmt.Sprintf("usage: %v <date|range>", ctx.Command())
+	args := scanArgs(req)
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+	iv, err := ctx.today.parseRange(strings.Join(args, " "))
+	if err != nil {
+		return fmt.Errorf("%w, %v", err, usage)
+	}
+	i := ctx.today.remnantSection()
+	if i < 0 {
+		return errors.New("no remnant item section configured, e.g. Done")
+	}
+	name := ctx.today.sectionNames[i]
+
+	if err := ctx.today.open(ctx.store); errors.Is(err, errStoreNotExists) {
+		return fmt.Errorf("no %v items found within %v, stream is empty", name, iv)
+	} else if err != nil {
+		return err
+	}
+	var report reportItem
+	if err := ctx.today.collectReport(&report, iv, ctx.today.sectionFilter(i)); err != nil {
+		return err
+	}
+	if len(report.items) == 0 {
+		return fmt.Errorf("no %v items found within %v", name, iv)
+	}
+
+	res.Break()
+	fmt.Fprintf(res, "# %v %v\n\n", name, iv)
+	return report.writeItems(res, "")
+}
+
+// reportItem is a node in a report tree, merging the outline items found under
+// the same parent path, like "[scanio] › arena", across many days.
+type reportItem struct {
+	title string
+	items []*reportItem
+}
+
+// collectReport adds the title path of every item matching filter, from all
+// days within the given interval, into the report tree. Day headings and
+// section headings, like "## Done", are elided from each path. Any toplevel
+// item that references an [area], without being just such a reference, is
+// grouped under that area.
+func (pres *presentDay) collectReport(into *reportItem, iv isotime.Interval, filter outlineFilter) error {
+	var (
+		path  []string
+		areas [][]byte
+	)
+	secs, err := pres.findDays(iv)
+	if err != nil {
+		return err
+	}
+	sc := &pres.sc
+	for _, sec := range secs {
+		for sc.Reset(sec.Token); sc.Scan(); {
+			if !sc.titled || !filter.match(&sc.outline) {
+				continue
+			}
+			path = path[:0]
+			for i, title := range sc.title {
+				b, _ := title.Bytes()
+				if len(b) == 0 {
+					continue
+				}
+				if sc.outline.block[i].Type == scandown.Heading && pres.matchSection(b) >= 0 {
+					continue
+				}
+				if len(path) == 0 {
+					if areas = sc.areas(areas[:0], i); len(areas) > 0 {
+						if area := "[" + string(areas[0]) + "]"; area != string(b) {
+							path = append(path, area)
+						}
+					}
+				}
+				path = append(path, string(b))
+			}
+			into.add(path)
+		}
+		if err := sc.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// add merges the given title path into the receiver's items, matching titles
+// case-insensitively.
+func (ri *reportItem) add(path []string) {
+	for _, title := range path {
+		var next *reportItem
+		for _, item := range ri.items {
+			if strings.EqualFold(item.title, title) {
+				next = item
+				break
+			}
+		}
+		if next == nil {
+			next = &reportItem{title: title}
+			ri.items = append(ri.items, next)
+		}
+		ri = next
+	}
+}
+
+// writeItems writes the receiver's items as a markdown list.
+func (ri *reportItem) writeItems(w io.Writer, indent string) error {
+	for _, item := range ri.items {
+		if _, err := fmt.Fprintf(w, "%v- %v\n", indent, item.title); err != nil {
+			return err
+		}
+		if err := item.writeItems(w, indent+"  "); err != nil {
+			return err
+		}
+	}
+	return nil
+}
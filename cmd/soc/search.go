@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/jcorbin/soc/internal/isotime"
+	"github.com/jcorbin/soc/internal/socui"
+)
+
+func init() {
+	builtinServer("search", serveSearch,
+		"search item titles and bodies throughout the stream")
+}
+
+func serveSearch(ctx *context, req *socui.Request, res *socui.Response) error {
+	flags := flag.NewFlagSet(ctx.Command(), flag.ContinueOnError)
+	flags.SetOutput(res)
+	tag := flags.String("tag", "", "only search items tagged with #name")
+	if err := flags.Parse(scanArgs(req)); errors.Is(err, flag.ErrHelp) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	usage := fmt.Sprintf("usage: %v [-tag name] <pattern>", ctx.Command())
+	var (
+		pattern *regexp.Regexp
+		filter  outlineFilter
+		what    []string
+	)
+	if *tag != "" {
+		name := strings.TrimPrefix(*tag, "#")
+		filter = outlineTagFilter(name)
+		what = append(what, "#"+name)
+	} else if flags.NArg() == 0 {
+		return errors.New(usage)
+	}
+	if flags.NArg() > 0 {
+		var err error
+		pattern, err = regexp.Compile(strings.Join(flags.Args(), " "))
+		if err != nil {
+			return fmt.Errorf("invalid search pattern: %w, %v", err, usage)
+		}
+		what = append(what, fmt.Sprintf("%q", pattern))
+	}
+
+	if err := ctx.today.open(ctx.store); errors.Is(err, errStoreNotExists) {
+		return fmt.Errorf("no matches for %v, stream is empty", strings.Join(what, " "))
+	} else if err != nil {
+		return err
+	}
+	res.Break()
+	if n, err := ctx.today.sc.search(res, filter, pattern); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("no matches for %v", strings.Join(what, " "))
+	}
+	return nil
+}
+
+// search scans the rest of the outline, writing a line for each item that
+// matches any filter and whose title or body content matches any pattern, as
+// it's found. Each line gives the item's day followed by its path of titles,
+// e.g.:
+//
+//	2020-09-03 › [scanio] › arena nil safety
+//
+// Returns the number of matched items.
+func (sc *outlineScanner) search(to io.Writer, filter outlineFilter, pattern *regexp.Regexp) (n int, _ error) {
+	var (
+		hit     []int // outline ids of matched items still along the outline path
+		content bytes.Buffer
+		line    bytes.Buffer
+	)
+	for sc.Scan() {
+		if len(sc.id) == 0 {
+			continue
+		}
+		hit = pruneIDs(hit, sc.id)
+		leaf := sc.id[len(sc.id)-1]
+		if len(hit) > 0 && hit[len(hit)-1] == leaf {
+			continue
+		}
+		if filter != nil && !filter.match(&sc.outline) {
+			continue
+		}
+
+		if pattern != nil {
+			content.Reset()
+			if _, err := content.ReadFrom(&sc.block); err != nil {
+				return n, err
+			}
+			if !pattern.Match(content.Bytes()) {
+				continue
+			}
+		}
+		hit = append(hit, leaf)
+		n++
+
+		line.Reset()
+		if t := truncateDay(sc.lastTime()); t.Grain() > 0 {
+			line.WriteString(t.String())
+		}
+		for _, title := range sc.title {
+			if title.Empty() {
+				continue
+			}
+			if line.Len() > 0 {
+				line.WriteString(" › ")
+			}
+			b, _ := title.Bytes()
+			line.Write(b)
+		}
+		line.WriteByte('\n')
+		if _, err := line.WriteTo(to); err != nil {
+			return n, err
+		}
+	}
+	return n, sc.Err()
+}
+
+// truncateDay truncates any time finer than a day, e.g. from an item like
+// "12:00 lunch", to its day.
+func truncateDay(t isotime.GrainedTime) isotime.GrainedTime {
+	if t.Grain() > isotime.TimeGrainDay {
+		return isotime.Time(t.Location(), t.Year(), t.Month(), t.Day(), 0, 0, 0)
+	}
+	return t
+}
+
+// pruneIDs truncates ids, a subsequence of some prior outline path, to only
+// those still along the given path.
+func pruneIDs(ids, path []int) []int {
+	i := 0
+	for j, id := range ids {
+		for i < len(path) && path[i] != id {
+			i++
+		}
+		if i == len(path) {
+			return ids[:j]
+		}
+	}
+	return ids
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/jcorbin/soc/internal/isotime"
+	"github.com/jcorbin/soc/internal/scanio"
+	"github.com/jcorbin/soc/internal/socui"
+	"github.com/jcorbin/soc/scandown"
+)
+
+func init() {
+	builtinServer("show", serveShow,
+		"print any day, or range of days, from the stream")
+}
+
+func serveShow(ctx *context, req *socui.Request, res *socui.Response) error {
+	flags := flag.NewFlagSet(ctx.Command(), flag.ContinueOnError)
+	flags.SetOutput(res)
+	raw := flags.Bool("raw", false, "print raw markdown, rather than an outline")
+	if err := flags.Parse(scanArgs(req)); errors.Is(err, flag.ErrHelp) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	usage := fmt.Sprintf("usage: %v [-raw] <date|range>", ctx.Command())
+	if flags.NArg() == 0 {
+		return errors.New(usage)
+	}
+	iv, err := ctx.today.parseRange(strings.Join(flags.Args(), " "))
+	if err != nil {
+		return fmt.Errorf("%w, %v", err, usage)
+	}
+
+	if err := ctx.today.open(ctx.store); errors.Is(err, errStoreNotExists) {
+		return fmt.Errorf("no days found within %v, stream is empty", iv)
+	} else if err != nil {
+		return err
+	}
+	secs, err := ctx.today.findDays(iv)
+	if err != nil {
+		return err
+	}
+	if len(secs) == 0 {
+		return fmt.Errorf("no days found within %v", iv)
+	}
+
+	res.Break()
+	for _, sec := range secs {
+		res.Break()
+		if *raw {
+			if _, err := scanio.CopyTokens(res, sec.Token); err != nil {
+				return err
+			}
+			continue
+		}
+		ctx.today.sc.Reset(sec.Token)
+		if err := ctx.today.sc.printOutline(res); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseRange parses a date, or a range of dates separated by "..", where each
+// date may be relative to the present day, e.g. "yesterday" or "monday..today".
+func (pres *presentDay) parseRange(s string) (iv isotime.Interval, err error) {
+	now := pres.date.Time()
+	from, to := s, s
+	if i := strings.Index(s, ".."); i >= 0 {
+		from, to = s[:i], s[i+2:]
+	}
+	if iv.From, err = isotime.ParseDate(now, strings.TrimSpace(from)); err != nil {
+		return iv, err
+	}
+	if iv.To, err = isotime.ParseDate(now, strings.TrimSpace(to)); err != nil {
+		return iv, err
+	}
+	if !iv.End().After(iv.Start()) {
+		return iv, fmt.Errorf("invalid range %q, %v is not before %v", s, iv.From, iv.To)
+	}
+	return iv, nil
+}
+
+// findDays returns all dated heading sections within the given interval, in
+// stream order; nested headings are returned within their parent's section.
+// Since the stream is kept in reverse chronological order, scanning stops at
+// the first toplevel heading that is entirely before the interval.
+func (pres *presentDay) findDays(iv isotime.Interval) (secs []section, _ error) {
+	sc := &pres.sc
+	var open section
+	for sc.Reset(pres.FileArena); sc.Scan(); {
+		if open = sc.updateSection(open); open.scanning {
+			continue
+		}
+		if open.id != 0 {
+			secs = append(secs, open)
+			open = section{}
+		}
+		if !sc.titled {
+			continue
+		}
+		blocks := sc.outline.block
+		if blocks[len(blocks)-1].Type != scandown.Heading {
+			continue
+		}
+		t := sc.lastTime()
+		if t.Grain() == 0 {
+			continue
+		}
+		if len(blocks) == 1 && !t.End().After(iv.Start()) {
+			break
+		}
+		if iv.Contains(t) {
+			open = sc.openSection()
+		}
+	}
+	if open = sc.updateSection(open); open.id != 0 {
+		secs = append(secs, open)
+	}
+	return secs, sc.Err()
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jcorbin/soc/internal/isotime"
+	"github.com/jcorbin/soc/internal/socui"
+)
+
+const stateFileName = ".soc-state.json"
+
+// uiState is user interaction state that persists between requests, stored
+// alongside the stream.
+type uiState struct {
+	// Choice is any prompt still awaiting a reply from the user.
+	Choice *pendingChoice `json:"choice,omitempty"`
+
+	// Last refers to the last item affected by a command.
+	Last *itemRef `json:"last,omitempty"`
+}
+
+// itemRef refers to an item by its title path within a present day section,
+// which remains stable as the stream is rescanned or the item moves between
+// sections.
+type itemRef struct {
+	Date isotime.GrainedTime `json:"date"`
+	Path []string            `json:"path"`
+}
+
+// lastItemArg is the command arg that refers to the last affected item.
+const lastItemArg = "."
+
+// pendingChoice records an ambiguous command, so that it may be re-run once
+// the user replies with the number of one of its options. Any -date option
+// that the command ran as of is recorded, resolved to its day, so that it's
+// re-run against the same day.
+type pendingChoice struct {
+	Command string   `json:"command"`
+	AsOf    string   `json:"asOf,omitempty"`
+	Options []string `json:"options"`
+}
+
+// chosenOption is the user's reply to a pendingChoice: the index of the
+// chosen option, along with the options that were offered.
+type chosenOption struct {
+	index   int
+	options []string
+}
+
+// loadState loads ui state from the store, if not already loaded.
+func (ctx *context) loadState() error {
+	if ctx.stateLoaded {
+		return nil
+	}
+	ctx.state = uiState{}
+	rc, err := ctx.store.sibling(stateFileName).open()
+	if errors.Is(err, errStoreNotExists) {
+		ctx.stateLoaded = true
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := json.NewDecoder(rc).Decode(&ctx.state); err != nil {
+		return fmt.Errorf("unable to decode %v: %w", stateFileName, err)
+	}
+	ctx.stateLoaded = true
+	return nil
+}
+
+// saveState writes ui state out to the store.
+func (ctx *context) saveState() error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(ctx.state); err != nil {
+		return err
+	}
+	return saveToStore(ctx.store.sibling(stateFileName), &buf)
+}
+
+// addMatchArgs adds args to am, expanding any leading lastItemArg into the
+// path of the last affected item. Returns true if that item was last affected
+// before today, so that the caller may also match against yesterday.
+func (ctx *context) addMatchArgs(am *argMatcher, args []string) (before bool, _ error) {
+	if len(args) > 0 && args[0] == lastItemArg {
+		if err := ctx.loadState(); err != nil {
+			return false, err
+		}
+		last := ctx.state.Last
+		if last == nil {
+			return false, errors.New("no last item to refer to")
+		}
+		if err := am.addPath(last.Path...); err != nil {
+			return false, err
+		}
+		// the date was unmarshaled in time.Local, not the configured zone
+		before = last.Date.In(ctx.today.date.Location()).Before(ctx.today.date)
+		args = args[1:]
+	}
+	return before, am.addArgs(args...)
+}
+
+// touchItem records the single item matched as the last affected item.
+func (ctx *context) touchItem(match *outlineMatch) error {
+	if match.groupCount() != 1 {
+		return nil
+	}
+	path, err := match.pathTitles(len(match.title) - 1)
+	if err != nil {
+		return err
+	}
+	if err := ctx.loadState(); err != nil {
+		return err
+	}
+	ctx.state.Last = &itemRef{ctx.today.date, path}
+	return ctx.saveState()
+}
+
+// choose asks the user to choose one of several options, returning the index
+// of the chosen option.
+//
+// When replying to a prior prompt, the index that the user chose is returned,
+// failing if the given options differ from those originally offered.
+// Otherwise a numbered prompt is written to res, and the choice is saved
+// pending a reply; in this case -1 is returned, and the caller should stop.
+func (ctx *context) choose(req *socui.Request, res *socui.Response, prompt string, options []string) (int, error) {
+	if chosen := ctx.chosen; chosen != nil {
+		ctx.chosen = nil
+		if !equalStrings(chosen.options, options) {
+			return -1, fmt.Errorf("chosen %q is no longer an option, the candidates have changed",
+				chosen.options[chosen.index])
+		}
+		return chosen.index, nil
+	}
+
+	if err := ctx.loadState(); err != nil {
+		return -1, err
+	}
+	choice := &pendingChoice{Command: req.Command(), Options: options}
+	if ctx.asOf != "" {
+		choice.AsOf = ctx.today.date.String()
+	}
+	ctx.state.Choice = choice
+	if err := ctx.saveState(); err != nil {
+		return -1, err
+	}
+
+	res.Break()
+	fmt.Fprintf(res, "%v:\n", prompt)
+	for i, option := range options {
+		fmt.Fprintf(res, "%v. %v\n", i+1, option)
+	}
+	fmt.Fprintf(res, "\nreply with `%v <number>` to choose\n", ctx.args[0])
+	return -1, nil
+}
+
+// serveChoice resolves any pending choice with the user's numbered reply,
+// re-running its command.
+func (mux serveMux) serveChoice(ctx *context, n int, req *socui.Request, res *socui.Response) error {
+	if err := ctx.loadState(); err != nil {
+		return err
+	}
+	choice := ctx.state.Choice
+	if choice == nil {
+		return errors.New("no pending choice to reply to")
+	}
+	if n < 1 || n > len(choice.Options) {
+		return fmt.Errorf("invalid choice %v, expected 1-%v", n, len(choice.Options))
+	}
+
+	ctx.state.Choice = nil
+	if err := ctx.saveState(); err != nil {
+		return err
+	}
+
+	// reload the present day, if the reply isn't as of the same date
+	if choice.AsOf != ctx.asOf {
+		defer func(asOf string) { ctx.asOf = asOf }(ctx.asOf)
+		ctx.asOf = choice.AsOf
+		date, preview, err := ctx.presentDate(req.Now())
+		if err != nil {
+			return err
+		}
+		if preview {
+			defer ctx.preview()()
+		}
+		ctx.today.date = date
+		if err := ctx.today.load(ctx.store); err != nil && !errors.Is(err, errStoreNotExists) {
+			return err
+		}
+	}
+
+	ctx.chosen = &chosenOption{n - 1, choice.Options}
+	defer func() { ctx.chosen = nil }()
+
+	sub := socui.StreamRequest(req.Now(), strings.NewReader(choice.Command))
+	if !sub.ScanArg() {
+		if err := sub.Err(); err != nil {
+			return err
+		}
+		return fmt.Errorf("invalid pending command %q", choice.Command)
+	}
+	ctx.args = ctx.args[:len(ctx.args)-1]
+	if err := mux.serveCommand(ctx, &sub, res); err != nil {
+		return err
+	}
+	return sub.Err()
+}
@@ -20,6 +20,14 @@ type store interface {
 	open() (io.ReadCloser, error)
 	create() (cleanupWriteCloser, error)
 	update() (cleanupWriteCloser, error)
+
+	// sibling returns a store for another named file alongside this one,
+	// e.g. for state or config kept next to the stream.
+	sibling(name string) store
+
+	// changed returns true if store content may have changed since it was
+	// last opened or written.
+	changed() bool
 }
 
 // sizedReaderAt converts the given read stream into a reader at, and returns it size.
@@ -101,14 +109,32 @@ type cleanupWriteCloser interface {
 }
 
 type memStore struct {
-	cur     string
-	defined bool
+	cur      string
+	defined  bool
+	siblings map[string]*memStore
+	rev      int // incremented by set
+	seenRev  int // rev when last opened
+}
+
+func (ms *memStore) changed() bool { return ms.rev != ms.seenRev }
+
+func (ms *memStore) sibling(name string) store {
+	if ms.siblings == nil {
+		ms.siblings = make(map[string]*memStore)
+	}
+	sib := ms.siblings[name]
+	if sib == nil {
+		sib = &memStore{siblings: ms.siblings}
+		ms.siblings[name] = sib
+	}
+	return sib
 }
 
 func (ms *memStore) open() (io.ReadCloser, error) {
 	if !ms.defined {
 		return nil, errStoreNotExists
 	}
+	ms.seenRev = ms.rev
 	return ioutil.NopCloser(strings.NewReader(ms.cur)), nil
 }
 
@@ -137,6 +163,7 @@ func (ms *memStore) pendBuf(sink func(string) error) *pendingBuffer {
 func (ms *memStore) set(content string) error {
 	ms.cur = content
 	ms.defined = true
+	ms.rev++
 	return nil
 }
 
@@ -176,25 +203,96 @@ func (pms *pendingBuffer) Cleanup() error {
 	return nil
 }
 
+// previewStore overlays another store, keeping any writes in memory rather
+// than writing them through; e.g. to preview a future day's rollover without
+// committing the stream to it. Siblings, like ui state, are not overlaid.
+type previewStore struct {
+	store
+	mem *memStore
+}
+
+func newPreviewStore(st store) *previewStore {
+	return &previewStore{st, &memStore{}}
+}
+
+func (ps *previewStore) open() (io.ReadCloser, error) {
+	if ps.mem.defined {
+		return ps.mem.open()
+	}
+	return ps.store.open()
+}
+
+func (ps *previewStore) create() (cleanupWriteCloser, error) {
+	if !ps.mem.defined {
+		rc, err := ps.store.open()
+		if err == nil {
+			rc.Close()
+			return nil, errStoreExists
+		} else if !errors.Is(err, errStoreNotExists) {
+			return nil, err
+		}
+	}
+	return ps.mem.create()
+}
+
+func (ps *previewStore) update() (cleanupWriteCloser, error) {
+	if !ps.mem.defined {
+		rc, err := ps.store.open()
+		if err != nil {
+			return nil, err
+		}
+		rc.Close()
+	}
+	return ps.mem.update()
+}
+
+func (ps *previewStore) changed() bool {
+	if ps.mem.defined {
+		return ps.mem.changed()
+	}
+	return ps.store.changed()
+}
+
 type fsStore struct {
 	filename string
 	fileinfo os.FileInfo
 }
 
-func (fst *fsStore) open() (io.ReadCloser, error) {
+func (fst *fsStore) sibling(name string) store {
+	return &fsStore{filename: filepath.Join(filepath.Dir(fst.filename), name)}
+}
+
+func (fst *fsStore) stat() error {
 	if fst.fileinfo == nil {
 		info, err := os.Stat(fst.filename)
-		if err != nil {
-			return nil, err
+		if os.IsNotExist(err) {
+			return errStoreNotExists
+		} else if err != nil {
+			return err
 		}
 		fst.fileinfo = info
 	}
-	if fst.fileinfo == nil {
-		return nil, errStoreNotExists
+	return nil
+}
+
+func (fst *fsStore) open() (io.ReadCloser, error) {
+	fst.fileinfo = nil // always re-stat, for later change detection
+	if err := fst.stat(); err != nil {
+		return nil, err
 	}
 	return os.Open(fst.filename)
 }
 
+func (fst *fsStore) changed() bool {
+	info, err := os.Stat(fst.filename)
+	if fst.fileinfo == nil || err != nil {
+		return fst.fileinfo != nil || err == nil
+	}
+	return !os.SameFile(info, fst.fileinfo) ||
+		info.Size() != fst.fileinfo.Size() ||
+		!info.ModTime().Equal(fst.fileinfo.ModTime())
+}
+
 func (fst *fsStore) create() (cleanupWriteCloser, error) {
 	if fst.fileinfo != nil {
 		return nil, errStoreExists
@@ -207,8 +305,8 @@ func (fst *fsStore) create() (cleanupWriteCloser, error) {
 }
 
 func (fst *fsStore) update() (cleanupWriteCloser, error) {
-	if fst.fileinfo == nil {
-		return nil, errStoreNotExists
+	if err := fst.stat(); err != nil {
+		return nil, err
 	}
 	f, err := ioutil.TempFile(filepath.Dir(fst.filename), "."+filepath.Base(fst.filename)+".tmp_*")
 	if err != nil {
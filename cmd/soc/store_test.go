@@ -1,10 +1,12 @@
 package main
 
 import (
+	"errors"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -48,8 +50,13 @@ func (st storeTest) run(t *testing.T) {
 		{"init create (actual)", st.writeWith(st.create, "actual")},
 		{"create should now fail", st.createFails},
 		{"read back", st.expect("actual")},
+		{"unchanged since read", st.expectChanged(false)},
 		{"update", st.writeWith(st.update, "actually")},
+		{"changed by update", st.expectChanged(true)},
 		{"read back 2", st.expect("actually")},
+		{"unchanged since read 2", st.expectChanged(false)},
+		{"sibling", st.sibling},
+		{"read back 3", st.expect("actually")},
 	} {
 		if !t.Run(step.name, step.fn) {
 			break
@@ -78,6 +85,27 @@ func (st storeTest) writeWith(open func() (cleanupWriteCloser, error), content s
 	}
 }
 
+func (st storeTest) sibling(t *testing.T) {
+	sib := st.store.sibling("sibling.json")
+	_, err := sib.open()
+	assert.True(t, errors.Is(err, errStoreNotExists), "sibling open should fail as not existing")
+	for _, content := range []string{"sib", "sibling"} {
+		require.NoError(t, saveToStore(sib, strings.NewReader(content)), "must save sibling")
+		r, err := st.store.sibling("sibling.json").open()
+		require.NoError(t, err, "must open sibling")
+		b, err := ioutil.ReadAll(r)
+		assert.NoError(t, err, "must read sibling")
+		assert.NoError(t, r.Close(), "must close sibling")
+		assert.Equal(t, content, string(b), "expected sibling content")
+	}
+}
+
+func (st storeTest) expectChanged(changed bool) func(t *testing.T) {
+	return func(t *testing.T) {
+		assert.Equal(t, changed, st.changed(), "expected store changed")
+	}
+}
+
 func (st storeTest) createFails(t *testing.T) {
 	_, err := st.create()
 	assert.Error(t, err, "create should fail")
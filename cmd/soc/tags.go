@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jcorbin/soc/internal/isotime"
+	"github.com/jcorbin/soc/internal/socui"
+)
+
+func init() {
+	builtinServer("tags", serveTags,
+		"list all #tags used in the stream, with counts and when last seen")
+	builtinServer("tag", serveTag,
+		"print all items carrying a #tag throughout the stream")
+}
+
+func serveTags(ctx *context, req *socui.Request, res *socui.Response) error {
+	if err := ctx.today.open(ctx.store); errors.Is(err, errStoreNotExists) {
+		return errors.New("no tags found, stream is empty")
+	} else if err != nil {
+		return err
+	}
+
+	type tagStat struct {
+		name  string
+		count int
+		last  isotime.GrainedTime
+	}
+	var (
+		stats []tagStat
+		index = make(map[string]int)
+		tags  [][]byte
+	)
+	sc := &ctx.today.sc
+	for sc.Scan() {
+		if !sc.titled {
+			continue
+		}
+		t := truncateDay(sc.lastTime())
+		tags = sc.tags(tags[:0], len(sc.title)-1)
+		for j, tag := range tags {
+			if containsTag(tags[:j], tag) {
+				continue // count each item once per tag
+			}
+			i, seen := index[string(tag)]
+			if !seen {
+				i = len(stats)
+				index[string(tag)] = i
+				stats = append(stats, tagStat{name: string(tag)})
+			}
+			stats[i].count++
+			if t.Grain() > 0 && (stats[i].last.Grain() == 0 || t.After(stats[i].last)) {
+				stats[i].last = t
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	if len(stats) == 0 {
+		return errors.New("no tags found")
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].count != stats[j].count {
+			return stats[i].count > stats[j].count
+		}
+		return stats[i].name < stats[j].name
+	})
+	res.Break()
+	for _, stat := range stats {
+		items := "items"
+		if stat.count == 1 {
+			items = "item"
+		}
+		if stat.last.Grain() > 0 {
+			fmt.Fprintf(res, "#%v %v %v, last seen %v\n", stat.name, stat.count, items, stat.last)
+		} else {
+			fmt.Fprintf(res, "#%v %v %v\n", stat.name, stat.count, items)
+		}
+	}
+	return nil
+}
+
+func serveTag(ctx *context, req *socui.Request, res *socui.Response) error {
+	args := scanArgs(req)
+	if len(args) != 1 || strings.TrimPrefix(args[0], "#") == "" {
+		return fmt.Errorf("usage: %v <name>", ctx.Command())
+	}
+	name := strings.TrimPrefix(args[0], "#")
+
+	if err := ctx.today.open(ctx.store); errors.Is(err, errStoreNotExists) {
+		return fmt.Errorf("no items tagged #%v, stream is empty", name)
+	} else if err != nil {
+		return err
+	}
+	res.Break()
+	if n, err := ctx.today.sc.search(res, outlineTagFilter(name), nil); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("no items tagged #%v", name)
+	}
+	return nil
+}
+
+// tags appends any #tags within the i-th title, lower cased and without their
+// leading '#'. Tags must start a word, and may contain letters, numbers, and
+// any of "-_/"; an all numeric tag, like "#123", is more likely an issue
+// reference, and so is ignored.
+func (out *outline) tags(into [][]byte, i int) [][]byte {
+	b, _ := out.title[i].Bytes()
+	for j := 0; j < len(b); j++ {
+		if b[j] != '#' {
+			continue
+		}
+		if j > 0 {
+			if r, _ := utf8.DecodeLastRune(b[:j]); !unicode.IsSpace(r) && r != '(' {
+				continue
+			}
+		}
+		k, numeric := j+1, true
+		for k < len(b) {
+			r, n := utf8.DecodeRune(b[k:])
+			if !isTagRune(r) {
+				break
+			}
+			numeric = numeric && unicode.IsDigit(r)
+			k += n
+		}
+		if k > j+1 && !numeric {
+			into = append(into, bytes.ToLower(b[j+1:k]))
+		}
+		j = k - 1
+	}
+	return into
+}
+
+func containsTag(tags [][]byte, tag []byte) bool {
+	for _, other := range tags {
+		if bytes.Equal(other, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func isTagRune(r rune) bool {
+	switch r {
+	case '-', '_', '/':
+		return true
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// outlineTagFilter matches outline items whose own title carries a #tag.
+type outlineTagFilter string
+
+func (name outlineTagFilter) match(out *outline) bool {
+	if len(out.title) == 0 {
+		return false
+	}
+	var buf [4][]byte
+	for _, tag := range out.tags(buf[:0], len(out.title)-1) {
+		if strings.EqualFold(string(tag), string(name)) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,11 +1,16 @@
 package main
 
 import (
+	"bytes"
 	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/jcorbin/soc/internal/isotime"
 	"github.com/jcorbin/soc/internal/scanio"
@@ -21,7 +26,12 @@ type ItemTypeConfig struct {
 	//
 	// May be used is a section header like `# TODO` or as an item prefix as in
 	// `- TODO thing to do`.
-	Name string
+	Name string `json:"name"`
+
+	// Aliases are alternate names, like "FIXME" for "TODO", that may be used
+	// in place of Name both as command triggers and in section headers. The
+	// default config provides none.
+	Aliases []string `json:"aliases,omitempty"`
 
 	// If Remains is true these sections are left behind during when we collect
 	// the present day (e.g. under the `today` command). Otherwise such
@@ -31,7 +41,7 @@ type ItemTypeConfig struct {
 	// prefixed items in a remnant section will remain. I.E. any `- TODO thing`
 	// notes left under the `# Done` section are not collected, but remain in
 	// the past.
-	Remains bool
+	Remains bool `json:"remains,omitempty"`
 }
 
 func compileItemConfigs(itemTypes []ItemTypeConfig) (names []string, remains []bool, pattern *regexp.Regexp, err error) {
@@ -43,16 +53,19 @@ func compileItemConfigs(itemTypes []ItemTypeConfig) (names []string, remains []b
 	}
 
 	{
-		str := `(?i:^\s*`
-		for i, name := range names {
-			name = regexp.QuoteMeta(name)
+		str := `(?i:^\s*(?:`
+		for i, itemType := range itemTypes {
+			alts := regexp.QuoteMeta(itemType.Name)
+			for _, alias := range itemType.Aliases {
+				alts += `|` + regexp.QuoteMeta(alias)
+			}
 			if i > 0 {
-				str += `|(` + name + `)`
+				str += `|(` + alts + `)`
 			} else {
-				str += `(` + name + `)`
+				str += `(` + alts + `)`
 			}
 		}
-		str += `\s*$)`
+		str += `)\s*$)`
 		pattern, err = regexp.Compile(str)
 	}
 
@@ -66,18 +79,40 @@ func init() {
 }
 
 func setupToday(ctx *context) (err error) {
-	builtinItemTypes := []ItemTypeConfig{
+	itemTypes := []ItemTypeConfig{
 		{Name: "TODO", Remains: false},
 		{Name: "WIP", Remains: false},
 		{Name: "Done", Remains: true},
 	}
+	if len(ctx.config.ItemTypes) > 0 {
+		itemTypes = ctx.config.ItemTypes
+	}
 
-	// TODO read stored config
-	ctx.today.sectionNames, ctx.today.sectionRemains, ctx.today.sectionPattern, err = compileItemConfigs(builtinItemTypes)
+	ctx.today.sectionNames, ctx.today.sectionRemains, ctx.today.sectionPattern, err = compileItemConfigs(itemTypes)
 	if err != nil {
 		return err
 	}
 
+	if layout := ctx.config.DayFormat; layout != "" {
+		if err := checkDayFormat(layout); err != nil {
+			return err
+		}
+	}
+	ctx.today.dayFormat = ctx.config.DayFormat
+
+	ctx.today.location = nil
+	if name := ctx.config.TimeZone; name != "" {
+		if ctx.today.location, err = time.LoadLocation(name); err != nil {
+			return fmt.Errorf("invalid time zone %q: %w", name, err)
+		}
+	}
+	ctx.today.dayStart = 0
+	if s := ctx.config.DayStart; s != "" {
+		if ctx.today.dayStart, err = parseDayStart(s); err != nil {
+			return err
+		}
+	}
+
 	for i, name := range ctx.today.sectionNames {
 		srv := serve(todayServer{name, int(firstVarSection) + i},
 			fmt.Sprintf("show/add/move %v today items", name),
@@ -86,6 +121,11 @@ func setupToday(ctx *context) (err error) {
 		if err := ctx.mux.handle(strings.ToLower(name), srv); err != nil {
 			return err
 		}
+		for _, alias := range itemTypes[i].Aliases {
+			if err := ctx.mux.alias(strings.ToLower(alias), strings.ToLower(name)); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
@@ -104,85 +144,338 @@ func (tod todayServer) serve(ctx *context, req *socui.Request, res *socui.Respon
 		return fmt.Errorf("unable to find %v %q section", ctx.today.date, tod.name)
 	}
 
+	flags := flag.NewFlagSet(ctx.Command(), flag.ContinueOnError)
+	flags.SetOutput(res)
+	withYesterday := flags.Bool("yesterday", false, "also match items left behind in yesterday's section")
+	raw := flags.Bool("raw", false, "print the section's raw markdown, rather than an outline")
+	rest, err := parseFlags(flags, scanArgs(req))
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
 	// collect remaining command args to match against items, adding any
 	// unmatched args as a new item
-	var reqArgs []string
-	for req.ScanArg() {
-		reqArgs = append(reqArgs, req.Arg())
-	}
-
-	// compile arg patterns
-	var patterns []*regexp.Regexp
-	if len(reqArgs) > 0 {
-		patterns = make([]*regexp.Regexp, len(reqArgs))
-		for i, arg := range reqArgs {
-			arg = strings.TrimSpace(arg)
-			pattern, err := regexp.Compile(`(?i:` + regexp.QuoteMeta(arg) + `)`)
-			if err != nil {
-				return fmt.Errorf("unable to compile regexp for arg[%v]:%q : %w", i+1, arg, err)
+	var am argMatcher
+	lastBefore, err := ctx.addMatchArgs(&am, rest)
+	if err != nil {
+		return err
+	}
+
+	// match as many args as possible against items throughout the present day
+	cands, err := am.matchPresent(&ctx.today, tod.index, *withYesterday || lastBefore)
+	if err != nil {
+		return err
+	}
+
+	var (
+		sec     = ctx.today.sections[tod.index]
+		match   *outlineMatch // matched items within sec
+		path    []string      // item path to replicate within sec
+		args    = am.args     // args remaining after match
+		touched bool          // true if an item was moved or added
+	)
+	if len(cands) > 0 {
+		best := cands[0]
+		if best.section == tod.index && len(best.rem) == 0 {
+			// show all fully matched items
+			if match, _, err = am.match(sec); err != nil {
+				return err
+			}
+		} else {
+			// have the user choose between any equally good candidates
+			if tied := cands.tied(); len(tied) > 1 || ctx.chosen != nil {
+				i, err := ctx.choose(req, res, fmt.Sprintf(
+					"ambiguous match for %q, choose one of %v candidate items",
+					am.args[:len(am.args)-len(best.rem)], len(tied),
+				), tied.options())
+				if err != nil || i < 0 {
+					return err
+				}
+				best = tied[i]
+			}
+
+			if best.section == tod.index {
+				// add under a partially matched item
+				match = best.match
+			} else if len(best.rem) > 0 {
+				// add under a better partially matched item elsewhere,
+				// replicating its path within this section
+				path = best.path
+			} else if tod.index < int(firstVarSection) {
+				return fmt.Errorf("matched %v, cannot move items directly under %v",
+					best, presentSection(tod.index))
+			} else {
+				// move a fully matched item from elsewhere
+				if err := ctx.today.moveItem(ctx.store, sec, best.match); err != nil {
+					return err
+				}
+				log.Printf("Moved from %v: %v", best.where, best.path[len(best.path)-1])
+				touched = true
+				sec = ctx.today.sections[tod.index]
+				if match, _, err = am.match(sec); err != nil {
+					return err
+				}
 			}
-			patterns[i] = pattern
 		}
+		args = best.rem
 	}
 
-	// doMatch tries to match as many arg as possible against a section,
-	// returning the match result set and any remaining args, or an error.
-	var msc outlineScanner
-	doMatch := func(sec section) (_ *outlineMatch, remArgs []string, err error) {
-		if len(reqArgs) == 0 {
-			return nil, nil, nil
+	if len(args) > 0 {
+		// add a new item based on the remaining args
+		if len(path) > 0 {
+			if match, path, err = ctx.today.matchPath(sec, path); err != nil {
+				return err
+			}
 		}
-		var match outlineMatch // collected match results to return
-		match.arena = &ctx.today
-		if err := msc.matchOutline(&match, sec.body(), patterns...); err != nil {
-			return nil, nil, err
+		if match.empty() && len(path) == 0 && tod.index < int(firstVarSection) {
+			return fmt.Errorf("no item matched %q, cannot add new items directly under %v",
+				am.args, presentSection(tod.index))
 		}
-		nextArg := match.maxNextArg()
-		remArgs = reqArgs[nextArg:]
-		return &match, remArgs, err
-	}
 
-	// match as many args as possible against prior items
-	// TODO also match against sibling sections
-	sec := ctx.today.sections[tod.index]
-	match, args, err := doMatch(sec)
-	if err != nil {
-		return err
+		text := strings.Join(args, " ")
+		if err := ctx.today.addItem(ctx.store, sec, match, path, text); err != nil {
+			return err
+		}
+
+		// re-match against the reloaded stream to find the new item
+		am.truncate(len(am.args) - len(args))
+		if err := am.addArg(text); err != nil {
+			return err
+		}
+		sec = ctx.today.sections[tod.index]
+		if match, _, err = am.match(sec); err != nil {
+			return err
+		}
+		touched = true
 	}
 
-	// add a new item based on the remaining args
-	if len(args) > 0 {
-		log.Printf("TODO add new %v item w/ %q", ctx.CommandHead(), args)
-		return errors.New("unimplemented")
+	if touched {
+		if err := ctx.touchItem(match); err != nil {
+			return err
+		}
 	}
 
 	res.Break()
 
+	// print the whole section as raw markdown, under today's header
+	if *raw {
+		var toks []scanio.Token
+		if today := ctx.today.sections[todaySection]; sec.Start() != today.Start() {
+			toks = append(toks, today.header())
+		}
+		_, err := scanio.CopyTokens(res, append(toks, sec.Token)...)
+		return err
+	}
+
 	fmt.Fprintf(res, "# %v\n", ctx.today.titles[tod.index])
 
 	// print matched/added item(s)
-	var (
-		filter outlineFilter
-	)
+	filter := match.filter()
+	ctx.today.sc.Reset(sec.body())
+	return ctx.today.sc.printOutline(res, filter)
+}
 
-	if !match.empty() {
-		// filter to any matched item or their children
-		filter = outlineFilters(filter, outlineFilterFunc(func(out *outline) bool {
-			_, n := match.matchIDs(out.id)
-			return n > 0
-		}))
+// argMatcher matches user command args against outline items within stream
+// sections; each arg is compiled into a pattern for use with
+// outlineScanner.matchOutline.
+type argMatcher struct {
+	args     []string
+	patterns []*regexp.Regexp
+	sc       outlineScanner
+}
+
+// addArgs adds any number of args, compiling their patterns.
+func (am *argMatcher) addArgs(args ...string) error {
+	for _, arg := range args {
+		if err := am.addArg(arg); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	// TODO option for raw markdown vs outline
-	// raw := io.MultiReader(sec.header().readerWithin(&ctx.today), body)
-	// if tod.index != int(todaySection) {
-	// 	todSec := ctx.today.sections[todaySection]
-	// 	raw = io.MultiReader(todSec.header().readerWithin(&ctx.today), raw)
-	// }
-	// _, err := io.Copy(res, raw)
+// addArg adds another arg, compiling its pattern.
+func (am *argMatcher) addArg(arg string) error {
+	pattern, err := compileArgPattern(arg)
+	if err != nil {
+		return fmt.Errorf("unable to compile regexp for arg[%v]:%q : %w", len(am.args)+1, arg, err)
+	}
+	am.args = append(am.args, arg)
+	am.patterns = append(am.patterns, pattern)
+	return nil
+}
 
-	ctx.today.sc.Reset(sec.body())
-	return ctx.today.sc.printOutline(res, filter)
+// addPath adds args that exactly match each title along an item path.
+func (am *argMatcher) addPath(path ...string) error {
+	for _, title := range path {
+		pattern, err := compileTitlePattern(title)
+		if err != nil {
+			return fmt.Errorf("unable to compile regexp for path[%v]:%q : %w", len(am.args)+1, title, err)
+		}
+		am.args = append(am.args, title)
+		am.patterns = append(am.patterns, pattern)
+	}
+	return nil
+}
+
+// truncate discards all but the first n args.
+func (am *argMatcher) truncate(n int) {
+	am.args = am.args[:n]
+	am.patterns = am.patterns[:n]
+}
+
+// match tries to match as many args as possible against a section,
+// returning the match result set and any remaining args, or an error.
+func (am *argMatcher) match(sec section) (_ *outlineMatch, remArgs []string, err error) {
+	if len(am.args) == 0 {
+		return nil, nil, nil
+	}
+	var match outlineMatch // collected match results to return
+	if err := am.sc.matchOutline(&match, sec.body(), am.patterns...); err != nil {
+		return nil, nil, err
+	}
+	return &match, am.args[match.maxNextArg():], nil
+}
+
+// matchPresent matches args against the present day, returning a candidate
+// for every distinct item matched. When prefer is a today sub-section, all of
+// its siblings are searched after it; if prefer is negative, all today
+// sub-sections are searched without preference. Yesterday is searched last if
+// withYesterday is true.
+//
+// Candidates are ranked by how many args they matched, then by section:
+// the preferred section first, then any others, then yesterday.
+func (am *argMatcher) matchPresent(pres *presentDay, prefer int, withYesterday bool) (cands itemCandidates, _ error) {
+	if len(am.args) == 0 {
+		return nil, nil
+	}
+
+	search := func(i, rank int) error {
+		sectionCands, err := am.matchSection(pres, i, rank)
+		cands = append(cands, sectionCands...)
+		return err
+	}
+	if prefer >= 0 {
+		if err := search(prefer, 0); err != nil {
+			return nil, err
+		}
+	}
+	if prefer < 0 || prefer >= int(firstVarSection) {
+		for i := int(firstVarSection); i < len(pres.sections); i++ {
+			if i != prefer {
+				if err := search(i, 1); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	if withYesterday {
+		if err := search(int(yesterdaySection), 2); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(cands, func(i, j int) bool {
+		return len(cands[i].rem) < len(cands[j].rem)
+	})
+	return cands, nil
+}
+
+// matchSection matches args against a single present day section, returning a
+// candidate for every distinct item that matched at least one arg.
+func (am *argMatcher) matchSection(pres *presentDay, i, rank int) (cands itemCandidates, _ error) {
+	sec := pres.sections[i]
+	if sec.id == 0 {
+		return nil, nil
+	}
+	match, rem, err := am.match(sec)
+	if err != nil || len(rem) == len(am.args) {
+		return nil, err
+	}
+	where := fmt.Sprint(pres.titles[i])
+	for _, m := range match.split() {
+		path, err := m.pathTitles(len(m.title) - 1)
+		if err != nil {
+			return nil, err
+		}
+		cands = append(cands, itemCandidate{i, rank, where, path, m, rem})
+	}
+	return cands, nil
+}
+
+// itemCandidate is a single item matched within a present day section.
+type itemCandidate struct {
+	section int           // present day section index
+	rank    int           // section preference rank, lower is better
+	where   string        // section title
+	path    []string      // matched item path titles
+	match   *outlineMatch // single group match result
+	rem     []string      // args remaining after match
+}
+
+func (c itemCandidate) String() string {
+	return strings.Join(append([]string{c.where}, c.path...), " › ")
+}
+
+// itemCandidates is a ranked list of candidate items.
+type itemCandidates []itemCandidate
+
+// tied returns all leading candidates that rank equally well. Partial match
+// candidates from different sections are considered the same if they share
+// the same path, since new items would be added under equivalent structure.
+func (cands itemCandidates) tied() (tied itemCandidates) {
+	if len(cands) == 0 {
+		return nil
+	}
+	best := cands[0]
+	tied = cands[:1:1]
+	for _, c := range cands[1:] {
+		if len(c.rem) != len(best.rem) || c.rank != best.rank {
+			break
+		}
+		if len(c.rem) > 0 && c.section != best.section && equalStrings(c.path, best.path) {
+			continue
+		}
+		tied = append(tied, c)
+	}
+	return tied
+}
+
+// options returns a description of each candidate for the user to choose from.
+func (cands itemCandidates) options() []string {
+	options := make([]string, len(cands))
+	for i, c := range cands {
+		options[i] = c.String()
+	}
+	return options
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// compileArgPattern compiles a case-insensitive pattern that matches the given
+// user arg literally.
+func compileArgPattern(arg string) (*regexp.Regexp, error) {
+	arg = strings.TrimSpace(arg)
+	return regexp.Compile(`(?i:` + regexp.QuoteMeta(arg) + `)`)
+}
+
+// compileTitlePattern compiles a case-insensitive pattern that matches only
+// the given title exactly, ignoring surrounding space.
+func compileTitlePattern(title string) (*regexp.Regexp, error) {
+	title = strings.TrimSpace(title)
+	return regexp.Compile(`(?i:^\s*` + regexp.QuoteMeta(title) + `\s*$)`)
 }
 
 func (sc *outlineScanner) matchOutline(into *outlineMatch, arena scanio.Arena, patterns ...*regexp.Regexp) error {
@@ -191,6 +484,7 @@ func (sc *outlineScanner) matchOutline(into *outlineMatch, arena scanio.Arena, p
 		xlate []scanio.Token // used to copy titles during result collection
 	)
 	sc.Reset(arena)
+	into.arena = arena
 	cur.arena = arena
 	for sc.Scan() {
 		for i, sec := range cur.within {
@@ -248,13 +542,16 @@ func (sc *outlineScanner) matchOutline(into *outlineMatch, arena scanio.Arena, p
 		// add new matched outline node(s) with a newly opened section
 		cur.pushPath(&sc.outline, nextArg, sc.openSection())
 	}
+	for i, sec := range cur.within {
+		cur.within[i] = sc.updateSection(sec)
+	}
 	xlate = cur.resultInto(into, xlate) // collect any last match
 
 	return sc.Err()
 }
 
 type outlineMatch struct {
-	arena scanio.Arena
+	arena scanio.Arena // the arena matched within
 
 	group   []int
 	matched []int
@@ -270,6 +567,74 @@ func (om *outlineMatch) empty() bool {
 	return om == nil || len(om.group) == 0
 }
 
+// groupCount returns how many distinct items were matched.
+func (om *outlineMatch) groupCount() (n int) {
+	if om != nil {
+		for i, g := range om.group {
+			if i == 0 || g != om.group[i-1] {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// childIndent returns the content indent width of the i-th matched block,
+// i.e. the indent that a new child list item should use.
+func (om *outlineMatch) childIndent(i int) (indent int) {
+	for j := i; j >= 0 && om.group[j] == om.group[i]; j-- {
+		if b := om.block[j]; b.Type == scandown.Item {
+			indent += b.Indent + b.Width
+		}
+	}
+	return indent
+}
+
+// pathTitles returns title strings for all non-empty titles in the i-th
+// matched item's group, up to and including it.
+func (om *outlineMatch) pathTitles(i int) (titles []string, _ error) {
+	j := i
+	for j > 0 && om.group[j-1] == om.group[i] {
+		j--
+	}
+	for ; j <= i; j++ {
+		if title := om.title[j]; !title.Empty() {
+			s, err := title.Text()
+			if err != nil {
+				return nil, err
+			}
+			titles = append(titles, s)
+		}
+	}
+	return titles, nil
+}
+
+// split returns a separate match for each distinct item matched.
+func (om *outlineMatch) split() (ms []*outlineMatch) {
+	for i := 0; i < len(om.group); {
+		m := &outlineMatch{arena: om.arena}
+		for j := i; i < len(om.group) && om.group[i] == om.group[j]; i++ {
+			b, _ := om.title[i].Bytes()
+			m.bar.Write(b)
+			m.push(0, om.matched[i], om.block[i], m.bar.Take(), om.nextArg[i], om.within[i])
+		}
+		ms = append(ms, m)
+	}
+	return ms
+}
+
+// filter returns an outline filter that matches any matched item, their
+// parents, or their children; returns nil if the match is empty.
+func (om *outlineMatch) filter() outlineFilter {
+	if om.empty() {
+		return nil
+	}
+	return outlineFilterFunc(func(out *outline) bool {
+		_, n := om.matchIDs(out.id)
+		return n > 0
+	})
+}
+
 func (om *outlineMatch) matchIDs(ids []int) (matchGroup, matchLen int) {
 	if matchGroup = -1; om != nil && len(ids) > 0 {
 		var (
@@ -340,7 +705,9 @@ func (om *outlineMatch) pushPath(out *outline, nextArg int, sec section) {
 		}
 	}
 	for i := groupLen; i < len(out.id); i++ {
-		fmt.Fprint(&om.bar, out.title[i])
+		if t := out.title[i]; !t.Empty() {
+			fmt.Fprint(&om.bar, t)
+		}
 		id := out.id[i]
 		block := out.block[i]
 		title := om.bar.Take()
@@ -363,6 +730,22 @@ func (om *outlineMatch) truncate(i int) {
 	om.bar.PruneTo(om.title)
 }
 
+// truncateGroups truncates the match to retain at most n groups.
+func (om *outlineMatch) truncateGroups(n int) {
+	if n <= 0 {
+		om.truncate(0)
+		return
+	}
+	for i, g := range om.group {
+		if i > 0 && g != om.group[i-1] {
+			if n--; n <= 0 {
+				om.truncate(i)
+				return
+			}
+		}
+	}
+}
+
 func (om *outlineMatch) maxNextArg() int {
 	r := 0
 	if om != nil {
@@ -423,6 +806,9 @@ type presentConfig struct {
 	sectionNames   []string
 	sectionRemains []bool
 	sectionPattern *regexp.Regexp
+	dayFormat      string
+	location       *time.Location // nil to use the location of the current time
+	dayStart       time.Duration  // time of day when a new present day starts
 }
 
 type presentDay struct {
@@ -435,6 +821,25 @@ type presentDay struct {
 	sections []section
 	titles   []scanio.Token
 	arena    scanio.ByteArena
+
+	future  section             // any Future section of planned items
+	planned []plannedSection    // planned content whose date has arrived
+	later   isotime.GrainedTime // any day after date that has sub-sections
+}
+
+// futureSectionName is the title of a toplevel section whose items are
+// planned for the date that prefixes each of them, e.g.
+// `- 2020-10-01 renew cert` under `# Future`.
+const futureSectionName = "Future"
+
+// plannedSection is stream content planned for a date that has arrived:
+// either a future day section sketched ahead of time, or a date-prefixed item
+// within the Future section.
+type plannedSection struct {
+	section
+	date  isotime.GrainedTime
+	day   bool           // true for an entire day section, false for a single item
+	block scandown.Block // the item's block, if not day
 }
 
 type presentSection int
@@ -477,9 +882,50 @@ func (pc presentConfig) matchSectionIndex(match []int) int {
 	return -1
 }
 
+// dayTitle returns a day section header title.
+func (pc presentConfig) dayTitle(t isotime.GrainedTime) string {
+	if pc.dayFormat == "" {
+		return t.String()
+	}
+	return t.Time().Format(pc.dayFormat)
+}
+
+// isDayRemnant returns true if the given title, remaining after parsing away
+// the time t, is empty or what dayTitle(t) would write after its date.
+func (pc presentConfig) isDayRemnant(t isotime.GrainedTime, title []byte) bool {
+	title = bytes.TrimSpace(title)
+	if len(title) == 0 {
+		return true
+	}
+	if pc.dayFormat == "" || t.Grain() != isotime.TimeGrainDay {
+		return false
+	}
+	_, rest, _ := isotime.GrainedTime{}.ParseString(pc.dayTitle(t))
+	return strings.Join(strings.Fields(rest), " ") == string(title)
+}
+
+// dayOf returns the present day at the given time: its date within the
+// configured location, or the prior date if before the configured day start.
+func (pc presentConfig) dayOf(now time.Time) isotime.GrainedTime {
+	if pc.location != nil {
+		now = now.In(pc.location)
+	}
+	year, month, day := now.Date()
+	date := isotime.Time(now.Location(), year, month, day, 0, 0, 0)
+	hour, minute, second := now.Clock()
+	clock := time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute + time.Duration(second)*time.Second
+	if clock < pc.dayStart {
+		date = date.Prev()
+	}
+	return date
+}
+
 // open resets receiver state and (re)opens its FileArena from the given store.
 func (pres *presentDay) open(st store) (rerr error) {
-	defer func() { pres.sc.Reset(pres.FileArena) }()
+	defer func() {
+		pres.sc.loc = pres.date.Location()
+		pres.sc.Reset(pres.FileArena)
+	}()
 
 	if err := pres.reset(); err != nil {
 		return err
@@ -503,6 +949,9 @@ func (pres *presentDay) reset() error {
 	pres.titles = make([]scanio.Token, base, max)
 	pres.arena.Reset()
 	pres.loaded = false
+	pres.future = section{}
+	pres.planned = pres.planned[:0]
+	pres.later = isotime.GrainedTime{}
 	return err
 }
 
@@ -510,9 +959,16 @@ func (pres *presentDay) reset() error {
 // opened reader within for further use (e.g. to actually do anything with
 // section contents).
 //
-// It will either find a today section or a yesterday section.
-// Within whichever one it finds, it then looks for the names listed in
-// todaySectionNames.
+// It finds any today section, and the most recent day before it as the
+// yesterday section. Within today, or yesterday if there is no today, it then
+// looks for the names listed in todaySectionNames.
+//
+// Any prior day found above those, dated after them but without any such
+// sub-sections, was only sketched ahead of time, and is collected as planned
+// content that is now due, along with any due items within a Future section.
+// If neither of the first two days found has any sub-sections, none were
+// sketched, and the scan stops there. Finally, any remnant sub-section whose
+// header was elided by rollover is found within today.
 func (pres *presentDay) load(st store) (rerr error) {
 	if err := pres.open(st); err != nil && !errors.Is(err, errStoreNotExists) {
 		return err
@@ -523,6 +979,15 @@ func (pres *presentDay) load(st store) (rerr error) {
 		}
 	}()
 
+	// days tracks all today or yesterday candidates, in case any of them
+	// turn out to be sketched plans
+	type dayCandidate struct {
+		i     presentSection
+		t     isotime.GrainedTime
+		title scanio.Token
+	}
+	var days []dayCandidate
+
 	// mark opens a new section, retaining its title bytes for later use.
 	mark := func(i presentSection) {
 		fmt.Fprint(&pres.arena, &pres.sc.outline)
@@ -530,21 +995,73 @@ func (pres *presentDay) load(st store) (rerr error) {
 		pres.titles[i] = pres.arena.Take()
 	}
 
+	// hasSubSections returns true if any today sub-section has been found.
+	hasSubSections := func() bool {
+		for _, sec := range pres.sections[firstVarSection:] {
+			if sec.id != 0 {
+				return true
+			}
+		}
+		return false
+	}
+
+	// demote moves any today or yesterday section found so far into the
+	// sketched list, so that another prior day may be found.
+	var sketched []plannedSection
+	demote := func() {
+		for _, day := range days {
+			if sec := pres.sections[day.i]; sec.id != 0 {
+				sketched = append(sketched, plannedSection{sec, day.t, true, scandown.Block{}})
+				pres.sections[day.i] = section{}
+				pres.titles[day.i] = scanio.Token{}
+			}
+		}
+	}
+
+	// track the most recent day after the present one, in case it turns out
+	// to have sub-sections
+	var (
+		laterDay     isotime.GrainedTime
+		laterSection section
+	)
+
 	// scan the stream...
 	for pres.sc.Scan() {
 		// ...ending any open sections that we are no longer within
 		for i, sec := range pres.sections {
 			pres.sections[i] = pres.sc.updateSection(sec)
 		}
+		laterSection = pres.sc.updateSection(laterSection)
+		pres.future = pres.sc.updateSection(pres.future)
+		for i, plan := range pres.planned {
+			pres.planned[i].section = pres.sc.updateSection(plan.section)
+		}
+		for i, plan := range sketched {
+			sketched[i].section = pres.sc.updateSection(plan.section)
+		}
 
 		// skip any markdown blocks that don't define an outline item title
 		if !pres.sc.titled {
 			continue
 		}
 
-		// skip any outline items that a time
+		// skip any outline items that a time, other than a Future section
 		t := pres.sc.lastTime()
 		if t.Grain() == 0 {
+			if title, isToplevel := pres.sc.heading(1); isToplevel && pres.future.id == 0 {
+				if b, _ := title.Bytes(); strings.EqualFold(string(b), futureSectionName) {
+					pres.future = pres.sc.openSection()
+				}
+			}
+			continue
+		}
+
+		// collect due items from any Future section
+		if pres.future.id != 0 && pres.sc.within(pres.future) {
+			if _, isItem := pres.sc.heading(2); isItem && pres.isDue(t) {
+				b := pres.sc.outline.block[len(pres.sc.outline.block)-1]
+				pres.planned = append(pres.planned, plannedSection{pres.sc.openSection(), t, false, b})
+			}
 			continue
 		}
 
@@ -552,25 +1069,74 @@ func (pres *presentDay) load(st store) (rerr error) {
 		title, isToplevel := pres.sc.heading(1)
 
 		// anything with an empty title (remnant) contains only the (already
-		// parsed away) time, so check for today or yesterday
-		if title.Empty() {
-			if t.Equal(pres.date) {
-				mark(todaySection)
-			} else if t.Grain() == isotime.TimeGrainDay {
-				if len(pres.sections) > int(firstVarSection) {
-					break
+		// parsed away) time, or a day title remnant, so check for today or
+		// yesterday
+		b, _ := title.Bytes()
+		if title.Empty() || isToplevel && pres.isDayRemnant(t, b) {
+			isToday := t.Equal(pres.date)
+			if !isToday && t.Grain() < isotime.TimeGrainDay {
+				// a coarser heading, like a month, is only ever planned;
+				// collect any that are due, unless found after a lived day
+				if b := pres.sc.outline.block[len(pres.sc.outline.block)-1]; b.Type == scandown.Heading &&
+					title.Empty() && pres.isDue(t) && !hasSubSections() {
+					pres.planned = append(pres.planned, plannedSection{pres.sc.openSection(), t, true, scandown.Block{}})
+				}
+				continue
+			}
+			if !isToday && (t.Grain() != isotime.TimeGrainDay || !t.Time().Before(pres.date.Time())) {
+				if blocks := pres.sc.outline.block; t.Grain() == isotime.TimeGrainDay && !pres.later.Any() &&
+					len(blocks) == 1 && blocks[0].Type == scandown.Heading {
+					laterDay, laterSection = t, pres.sc.openSection()
 				}
-				mark(yesterdaySection)
+				continue
+			}
+			if len(days) > 1 && !hasSubSections() {
+				// neither of the first two days had sub-sections, so there's
+				// no lived day to find
+				break
+			} else if len(days) > 0 && !hasSubSections() {
+				// any day found so far without sub-sections may have only
+				// been sketched
+				demote()
+			} else if !isToday && pres.sections[yesterdaySection].id != 0 {
+				break
 			}
+			i := yesterdaySection
+			if isToday {
+				i = todaySection
+			}
+			mark(i)
+			days = append(days, dayCandidate{i, t, pres.titles[i]})
 			continue
 		}
+		if !isToplevel && pres.isDayRemnant(t, b) {
+			title, isToplevel = pres.sc.heading(2)
+		}
 		if !isToplevel {
 			continue
 		}
 
+		// a later day with sub-sections was already lived, rather than planned
+		if laterSection.id != 0 && pres.sc.within(laterSection) {
+			if b, _ := title.Bytes(); pres.matchSection(b) >= 0 {
+				pres.later, laterSection = laterDay, section{}
+			}
+			continue
+		}
+
+		// only look for sub-sections within today, or yesterday if there's
+		// no today section
+		within := pres.sections[todaySection]
+		if within.id == 0 {
+			within = pres.sections[yesterdaySection]
+		}
+		if within.id == 0 || !pres.sc.within(within) {
+			continue
+		}
+
 		// match the item title against the recognizer pattern;
 		// the group number that matches provides the sub-section index
-		b, _ := title.Bytes()
+		b, _ = title.Bytes()
 		if i := pres.matchSection(b); i >= 0 {
 			// allocate storage for this sub-section and then open
 			j := int(firstVarSection) + i
@@ -586,19 +1152,142 @@ func (pres *presentDay) load(st store) (rerr error) {
 	for i, sec := range pres.sections {
 		pres.sections[i] = pres.sc.updateSection(sec)
 	}
+	pres.future = pres.sc.updateSection(pres.future)
+	for i, plan := range pres.planned {
+		pres.planned[i].section = pres.sc.updateSection(plan.section)
+	}
+
+	for i, plan := range sketched {
+		sketched[i].section = pres.sc.updateSection(plan.section)
+	}
+
+	// if no day had any sub-sections, then none were sketched after all: the
+	// first day was demoted, so restore it (and the prior day after it, if it
+	// was today) instead
+	if len(days) > 1 && !hasSubSections() {
+		last := days[len(days)-1].i
+		secs := make([]section, 0, len(days))
+		for _, plan := range sketched {
+			secs = append(secs, plan.section)
+		}
+		secs = append(secs, pres.sections[last])
+		pres.sections[last], pres.titles[last] = section{}, scanio.Token{}
+		for j, day := range days[:2] {
+			if j > 0 && (day.i != yesterdaySection || days[0].i != todaySection) {
+				break
+			}
+			pres.sections[day.i], pres.titles[day.i] = secs[j], day.title
+		}
+		sketched = nil
+	}
+
+	// only a day dated after the lived one was actually sketched ahead of time
+	// (as defer does, only ever writing days after the present one); any other
+	// was just logged out of order, and is left alone
+	if len(days) > 0 && hasSubSections() {
+		lived := days[len(days)-1].t
+		keep := sketched[:0]
+		for _, plan := range sketched {
+			if plan.date.After(lived) {
+				keep = append(keep, plan)
+			}
+		}
+		sketched = keep
+	}
+	pres.planned = append(pres.planned, sketched...)
+
+	if err := pres.sc.Err(); err != nil {
+		return err
+	}
+	return pres.findElidedSection()
+}
+
+// findElidedSection finds any remnant sub-section (e.g. Done) within today
+// whose header was elided when the stream was rolled forward, leaving its
+// items directly under the day heading; e.g. when running as of a past day.
+// Such a section spans the day heading, and any content before the first
+// other sub-section, so that items may be added to it just as rollover left it.
+func (pres *presentDay) findElidedSection() error {
+	today, i := pres.sections[todaySection], pres.remnantSection()
+	if today.id == 0 || i < 0 {
+		return nil
+	}
+	j := int(firstVarSection) + i
+	if j < len(pres.sections) && pres.sections[j].id != 0 {
+		return nil
+	}
+
+	end := today.End()
+	for _, sec := range pres.sections[firstVarSection:] {
+		if sec.id != 0 && sec.Start() < end {
+			end = sec.Start()
+		}
+	}
+	sec := section{Token: today.Slice(0, end-today.Start()), bodyStart: today.bodyStart, id: today.id}
+	if end != today.End() {
+		b, err := sec.body().Bytes()
+		if err != nil {
+			return err
+		}
+		if len(bytes.TrimSpace(b)) == 0 {
+			return nil // no elided content before other sub-sections
+		}
+	}
 
-	return pres.sc.Err()
+	if n := j - len(pres.sections) + 1; n > 0 {
+		pres.sections = append(pres.sections, make([]section, n)...)
+		pres.titles = append(pres.titles, make([]scanio.Token, n)...)
+	}
+	fmt.Fprintf(&pres.arena, "%v %v", pres.titles[todaySection], pres.sectionNames[i])
+	pres.sections[j], pres.titles[j] = sec, pres.arena.Take()
+	return nil
+}
+
+// planSection returns the index of the first non-remnant today sub-section
+// name (e.g. TODO), that planned items are pulled into or deferred from; -1
+// if there is no such section.
+func (pc presentConfig) planSection() int {
+	for i, remains := range pc.sectionRemains {
+		if !remains {
+			return i
+		}
+	}
+	return -1
+}
+
+// remnantSection returns the index of the first remnant today sub-section
+// name (e.g. Done), whose header is elided from prior days once rolled over;
+// -1 if there is no such section.
+func (pc presentConfig) remnantSection() int {
+	for i, remains := range pc.sectionRemains {
+		if remains {
+			return i
+		}
+	}
+	return -1
+}
+
+// isDue returns true if the given planned time has arrived by the present day.
+func (pres *presentDay) isDue(t isotime.GrainedTime) bool {
+	return t.Time().Before(pres.date.Next().Time())
 }
 
 // collect performs a stream update if no today section has been found, writing
 // a new today section, collecting any non-remnant yesterday content (e.g.
-// TODO/WIP items), and ensuring that all today sub-sections are present.
+// TODO/WIP items), and ensuring that all today sub-sections are present. Any
+// planned items that are now due are pulled into the first non-remnant
+// sub-section (e.g. TODO).
 func (pres *presentDay) collect(st store, res *socui.Response) error {
 	if pres.sections[todaySection].id != 0 {
 		return nil
 	}
+	if pres.later.Any() {
+		return fmt.Errorf("cannot roll the stream back to %v, it already has %v", pres.date, pres.later)
+	}
 	// under a pending atomic update
 	return pres.edit(st, func(ed *scanio.Editor) error {
+		var pulled []string
+
 		// write the user a message on the way out
 		defer func() {
 			if pres.sections[yesterdaySection].id != 0 {
@@ -606,17 +1295,43 @@ func (pres *presentDay) collect(st store, res *socui.Response) error {
 			} else {
 				log.Printf("Created new Today section at top of stream")
 			}
+			for _, desc := range pulled {
+				log.Printf("Pulled %v", desc)
+			}
 		}()
 
+		// pull any due planned items, before placing the cursor, since
+		// their removal may shift it
+		pullInto := pres.planSection()
+		var (
+			pulledItems bytes.Buffer
+			removed     []scanio.Token
+		)
+		if pullInto >= 0 {
+			var err error
+			if removed, pulled, err = pres.pullPlanned(&pulledItems); err != nil {
+				return err
+			}
+			for _, tok := range removed {
+				ed.Remove(tok)
+			}
+		}
+
 		// if we found yesterday, cut stream content in half before/after its
 		// head, and then copy the head
 		cur := ed.CursorAt(0)
 		if sec := pres.sections[yesterdaySection]; sec.id != 0 {
-			cur.To(sec.Start())
+			loc := sec.Start()
+			for _, tok := range removed {
+				if tok.End() <= sec.Start() {
+					loc -= tok.Len()
+				}
+			}
+			cur.To(loc)
 		}
 
 		// write the new today section header
-		fmt.Fprintf(cur, "# %v\n\n", pres.date)
+		fmt.Fprintf(cur, "# %v\n\n", pres.dayTitle(pres.date))
 
 		// track yesterday body remnant for potential header elision
 		var remnant scanio.Area
@@ -636,12 +1351,28 @@ func (pres *presentDay) collect(st store, res *socui.Response) error {
 				sec = pres.sections[j]
 			}
 
-			if sec.id == 0 {
+			if pull := i == pullInto && pulledItems.Len() > 0; sec.id == 0 {
 				// add any missing sub-sections
 				fmt.Fprintf(cur, "## %v\n\n", name)
-			} else if !pres.sectionRemains[i] {
+				if pull {
+					pulledItems.WriteString("\n")
+					pulledItems.WriteTo(cur)
+				}
+			} else if !pres.sectionRemains[i] && !pull {
 				// carry forward non-remnant sub-sections (e.g. TODO and WIP)
 				cur.Insert(remove(sec.Token))
+			} else if !pres.sectionRemains[i] {
+				// ...adding any pulled items after its content
+				content, eol, err := trimBlankLines(remove(sec.Token))
+				if err != nil {
+					return err
+				}
+				cur.Insert(content)
+				if !eol {
+					cur.WriteString("\n")
+				}
+				pulledItems.WriteString("\n")
+				pulledItems.WriteTo(cur)
 			} else {
 				// leave remnant sections behind (e.g. Done)
 
@@ -657,14 +1388,133 @@ func (pres *presentDay) collect(st store, res *socui.Response) error {
 				// move or copy the yesterday sub-header into today
 				cur.Insert(header)
 			}
-
-			// TODO support pulling down future items
 		}
 
 		return nil
 	})
 }
 
+// pullPlanned writes all due planned items into w as new toplevel items, each
+// noting the date it was planned for, returning stream tokens to remove and a
+// description of each item pulled. Planned day sections are removed entirely,
+// unless they contain anything other than toplevel items.
+func (pres *presentDay) pullPlanned(w io.Writer) (removed []scanio.Token, pulled []string, _ error) {
+	pull := func(item scanio.Token, b scandown.Block, plan plannedSection) error {
+		item, _, err := trimBlankLines(item)
+		if err != nil {
+			return err
+		}
+		title, err := writePlannedItem(w, item, b, plan.date, !plan.day)
+		if err != nil {
+			return err
+		}
+		pulled = append(pulled, fmt.Sprintf("from %v: %v", plan.date, title))
+		removed = append(removed, item)
+		return nil
+	}
+
+	for _, plan := range pres.planned {
+		if !plan.day {
+			if err := pull(plan.Token, plan.block, plan); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+
+		// pull all toplevel items from a sketched day
+		var (
+			sc     outlineScanner
+			items  []section
+			blocks []scandown.Block
+		)
+		for sc.Reset(plan.body()); sc.Scan(); {
+			for i, item := range items {
+				items[i] = sc.updateSection(item)
+			}
+			if !sc.titled {
+				continue
+			}
+			if len(sc.outline.block) == 2 && sc.outline.block[0].Type == scandown.List {
+				if b := sc.outline.block[1]; b.Type == scandown.Item {
+					items = append(items, sc.openSection())
+					blocks = append(blocks, b)
+				}
+			}
+		}
+		for i, item := range items {
+			items[i] = sc.updateSection(item)
+		}
+		if err := sc.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		var rest scanio.Area
+		rest.Add(plan.body())
+		mark := len(removed)
+		for i, item := range items {
+			if err := pull(item.Token, blocks[i], plan); err != nil {
+				return nil, nil, err
+			}
+			rest.Sub(removed[len(removed)-1])
+		}
+
+		// remove the whole day, rather than its items, if nothing else remains
+		blank := true
+		for _, tok := range rest.AppendTokens(nil) {
+			b, err := tok.Bytes()
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(bytes.TrimSpace(b)) > 0 {
+				blank = false
+				break
+			}
+		}
+		if blank {
+			removed = append(removed[:mark], plan.Token)
+		}
+	}
+	return removed, pulled, nil
+}
+
+// writePlannedItem writes a planned list item into w as a new toplevel item,
+// with a final child item noting the date it was planned for. Any date prefix
+// is first removed from its title if stripDate is true. Returns the title of
+// the written item.
+func writePlannedItem(w io.Writer, item scanio.Token, b scandown.Block, date isotime.GrainedTime, stripDate bool) (string, error) {
+	content, err := item.Bytes()
+	if err != nil {
+		return "", err
+	}
+	line := content
+	if i := bytes.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i+1]
+	}
+	text := line
+	if n := b.Indent + b.Width; n <= len(text) {
+		text = text[n:]
+	}
+	if stripDate {
+		if _, rest, parsed := (isotime.GrainedTime{}).Parse(text); parsed {
+			text = bytes.TrimLeft(rest, " ")
+		}
+	}
+	title := string(bytes.TrimSpace(text))
+
+	var buf bytes.Buffer
+	buf.WriteString("- ")
+	buf.Write(text)
+	if err := reindentInto(&buf, item.Slice(len(line), -1), 2-(b.Indent+b.Width)); err != nil {
+		return "", err
+	}
+	if buf.Bytes()[buf.Len()-1] != '\n' {
+		buf.WriteString("\n")
+	}
+	fmt.Fprintf(&buf, "  - planned for %v\n", date)
+	_, err = buf.WriteTo(w)
+	return title, err
+}
+
 // edit runs the given function with an editor loaded with the currently
 // scanned stream's content. If with returns nil error, the editor content is
 // then written out to an atomic store update. If all of that succeeds, the
@@ -1,10 +1,13 @@
 package main
 
 import (
+	"bytes"
 	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 	"time"
@@ -18,6 +21,13 @@ type context struct {
 	mux   serveMux
 	store store
 	today presentDay
+
+	config      Config
+	state       uiState
+	stateLoaded bool
+	chosen      *chosenOption // option chosen when replying to a pending choice
+	interactive bool          // true when serving requests from an interactive loop
+	asOf        string        // date to use as the present day, rather than now
 }
 
 type server interface {
@@ -30,6 +40,13 @@ type helpServer interface {
 	help() server
 }
 
+// aliasServer is a server registered under an alternate name; aliases are
+// listed under their aliased command, rather than as separate commands.
+type aliasServer struct {
+	server
+	of string
+}
+
 type serverFunc func(*context, *socui.Request, *socui.Response) error
 
 type serverHelp struct {
@@ -45,6 +62,14 @@ func (fn serverFunc) serve(ctx *context, req *socui.Request, res *socui.Response
 func (sh serverHelp) describe() string { return sh.d }
 func (sh serverHelp) help() server     { return sh.h }
 
+func (as aliasServer) describe() string { return fmt.Sprintf("alias for %v", as.of) }
+func (as aliasServer) help() server {
+	if hs, ok := as.server.(helpServer); ok {
+		return hs.help()
+	}
+	return nil
+}
+
 func textServer(text string) tmplServer {
 	tmpl := template.Must(template.New("").Funcs(serverTemplateFuncs).Parse(text))
 	return tmplServer{tmpl}
@@ -70,6 +95,15 @@ func (mux serveMux) handle(name string, srv server) error {
 	return nil
 }
 
+// alias registers another name for an already handled command.
+func (mux serveMux) alias(name, of string) error {
+	srv := mux[of]
+	if srv == nil {
+		return fmt.Errorf("cannot alias %q to undefined %q server", name, of)
+	}
+	return mux.handle(name, aliasServer{srv, of})
+}
+
 func (mux serveMux) handleFunc(name string, srv interface{}, args ...interface{}) error {
 	return mux.handle(name, serve(srv, args...))
 }
@@ -124,10 +158,57 @@ func (ctx *context) Close() error {
 	return ctx.today.Close()
 }
 
+// scanArgs collects all remaining request args.
+func scanArgs(req *socui.Request) (args []string) {
+	for req.ScanArg() {
+		args = append(args, req.Arg())
+	}
+	return args
+}
+
+// parseFlags parses any leading flags from args, returning the remaining
+// args. Unlike flags.Parse, parsing stops at the first arg that isn't a
+// defined flag, so that the rest may start with a dash, like the text of a new
+// item, or a negated query term like -tag:name.
+func parseFlags(flags *flag.FlagSet, args []string) ([]string, error) {
+	i := 0
+	for ; i < len(args); i++ {
+		if args[i] == "--" {
+			i++
+			break
+		}
+		name := strings.TrimLeft(args[i], "-")
+		hasValue := false
+		if j := strings.IndexByte(name, '='); j >= 0 {
+			name, hasValue = name[:j], true
+		}
+		if name == args[i] {
+			break
+		}
+		if name == "h" || name == "help" {
+			continue
+		}
+		f := flags.Lookup(name)
+		if f == nil {
+			break
+		}
+		if bf, ok := f.Value.(interface{ IsBoolFlag() bool }); !hasValue && !(ok && bf.IsBoolFlag()) {
+			i++ // skip the flag's value
+		}
+	}
+	if i > len(args) {
+		i = len(args)
+	}
+	if err := flags.Parse(args[:i]); err != nil {
+		return nil, err
+	}
+	return append(flags.Args(), args[i:]...), nil
+}
+
 func (mux serveMux) Commands() []string {
 	var names []string
-	for name := range mux {
-		if name != "" {
+	for name, srv := range mux {
+		if _, isAlias := srv.(aliasServer); name != "" && !isAlias {
 			names = append(names, name)
 		}
 	}
@@ -140,7 +221,11 @@ func (mux serveMux) Commands() []string {
 
 func (mux serveMux) Describe(name string) string {
 	if hs, _ := mux[name].(helpServer); hs != nil {
-		return hs.describe()
+		desc := hs.describe()
+		if aliases := mux.aliases(name); len(aliases) > 0 {
+			desc += fmt.Sprintf(" (aliases: %v)", strings.Join(aliases, ", "))
+		}
+		return desc
 	}
 	if name == "help" {
 		return "show help overview or on a specific topic or command"
@@ -148,6 +233,17 @@ func (mux serveMux) Describe(name string) string {
 	return ""
 }
 
+// aliases returns a sorted list of all aliases for the named command.
+func (mux serveMux) aliases(of string) (names []string) {
+	for name, srv := range mux {
+		if as, isAlias := srv.(aliasServer); isAlias && as.of == of && name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 func (mux serveMux) helpTopics() serveMux {
 	topics, _ := mux[".helpTopics"].(serveMux)
 	return topics
@@ -185,6 +281,9 @@ func (mux serveMux) serveCommand(ctx *context, req *socui.Request, res *socui.Re
 	if name == "help" {
 		return mux.serveHelp(ctx, req, res)
 	}
+	if n, err := strconv.Atoi(name); err == nil {
+		return mux.serveChoice(ctx, n, req, res)
+	}
 	fmt.Fprintf(res, "unrecognized command %q\n", name)
 	// TODO help / feedback / advice / fuzzy match?
 	return nil
@@ -374,58 +473,101 @@ func (sf setupFunc) apply(ctx *context) error     { return sf(ctx) }
 // TODO builtinHelpTopic("matching")
 // TODO some sort of better builtinServer("", ...): display a today summarya,
 // an intro on first run, or maybe look for toplevel -h[elp] flags
-// TODO load user config from storage (extension?)
-// TODO user aliases and other customizations
 
 type ui struct {
-	builtinsRan error
 	context
+	configData []byte // raw config last loaded, to detect changes
 }
 
-var done = errors.New("done")
-
-func (ui *ui) init() error {
+// configure (re)loads user config, and then (re)runs all builtin setup if
+// this is the first request, or if the config has changed since the last;
+// returns true in that case.
+func (ui *ui) configure() (bool, error) {
 	if ui.store == nil {
 		ui.store = &memStore{}
 	}
-
-	if ui.mux == nil {
-		ui.mux = make(serveMux)
+	cfg, raw, err := loadConfig(ui.store.sibling(configFileName))
+	if err != nil {
+		return false, err
+	}
+	if ui.mux != nil && bytes.Equal(raw, ui.configData) {
+		return false, nil
 	}
+	ui.config, ui.configData = cfg, raw
+	return true, ui.init()
+}
 
-	err := ui.builtinsRan
-	if err == nil {
-		for _, builtin := range builtins {
-			if err := builtin.apply(&ui.context); err != nil {
-				ui.builtinsRan = err
-				break
-			}
+func (ui *ui) init() error {
+	ui.mux = make(serveMux)
+	for _, builtin := range builtins {
+		if err := builtin.apply(&ui.context); err != nil {
+			ui.mux = nil
+			return err
 		}
-		ui.builtinsRan = done
 	}
-	if err == done {
-		err = nil
+	if err := setupConfig(&ui.context); err != nil {
+		ui.mux = nil
+		return err
+	}
+	return nil
+}
+
+// presentDate returns the present day at the given time, or as of any -date
+// option. Any day after the actual present one is only to be previewed, since
+// committing the stream to it would lock out any further use of the actual
+// present day.
+func (ctx *context) presentDate(now time.Time) (date isotime.GrainedTime, preview bool, err error) {
+	date = ctx.today.dayOf(now)
+	if ctx.asOf == "" {
+		return date, false, nil
+	}
+	present := date
+	if date, err = isotime.ParseDate(date.Time(), ctx.asOf); err != nil {
+		return date, false, fmt.Errorf("invalid -date option: %w", err)
+	} else if date.Grain() != isotime.TimeGrainDay {
+		return date, false, fmt.Errorf("invalid -date option %v, must be a day", date)
+	}
+	return date, date.After(present), nil
+}
+
+// preview overlays the store, so that any changes are only previewed rather
+// than saved, returning a function that restores it.
+func (ctx *context) preview() (restore func()) {
+	st := ctx.store
+	ctx.store = newPreviewStore(st)
+	return func() {
+		ctx.store = st
+		ctx.today.loaded = false
 	}
-	return err
 }
 
 func (ui *ui) ServeUser(req *socui.Request, res *socui.Response) (rerr error) {
 	defer logs.restore()()
 	logs.setOutput(res).setFlags(0)
 
-	if ui.mux == nil {
-		if err := ui.init(); err != nil {
-			return err
-		}
+	reconfigured, err := ui.configure()
+	if err != nil {
+		return err
 	}
 
 	ctx := &ui.context
-
-	{
-		year, month, day := req.Now().Date()
-		ctx.today.date = isotime.Time(time.Local, year, month, day, 0, 0, 0)
+	// serveCommand appends each dispatched command name to ctx.args; restore
+	// them so that any later request on this ui (e.g. under repl) reports its
+	// own command, and not the accumulated names of all prior ones.
+	defer func(args []string) { ctx.args = args }(ctx.args)
+	ctx.stateLoaded = false
+
+	date, preview, err := ctx.presentDate(req.Now())
+	if err != nil {
+		return err
+	}
+	if preview {
+		defer ctx.preview()()
 	}
 	defer func() {
+		if ctx.interactive {
+			return // stay warm for the next request
+		}
 		if cerr := ctx.Close(); rerr == nil {
 			rerr = cerr
 		}
@@ -434,8 +576,15 @@ func (ui *ui) ServeUser(req *socui.Request, res *socui.Response) (rerr error) {
 	// try to load today, ignoring any not exists error; hereafter, a handler
 	// may check ctx.today.src == nil and either error, or perform
 	// initialization
-	if err := ctx.today.load(ctx.store); err != nil && !errors.Is(err, errStoreNotExists) {
-		return err
+	//
+	// an interactive ui only reloads when something may have changed since
+	// its prior request
+	if !ctx.interactive || reconfigured || preview || !ctx.today.loaded ||
+		!ctx.today.date.Equal(date) || ctx.store.changed() {
+		ctx.today.date = date
+		if err := ctx.today.load(ctx.store); err != nil && !errors.Is(err, errStoreNotExists) {
+			return err
+		}
 	}
 
 	return ui.mux.serve(ctx, req, res)
@@ -2,13 +2,16 @@ package main
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"regexp"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/jcorbin/soc/internal/lineedit"
 	"github.com/jcorbin/soc/internal/socui"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -136,9 +139,1691 @@ func Test_ui(t *testing.T) {
 			"# 2020-07-24 TODO",
 			"1. the other thing",
 		)),
+
+		cmd([]string{"todo", "a new thing"}, expectLines(
+			"# 2020-07-24 TODO",
+			"1. a new thing",
+		)),
+		expectStream(expectLines(
+			"# 2020-07-24",
+			"",
+			"## TODO",
+			"- the other thing",
+			"- and then...",
+			"- a new thing",
+			"## WIP",
+			"- that",
+			"## Done",
+			"# 2020-07-23",
+			"- this",
+			"# 2020-07-22",
+			"- different things",
+		)),
+
+		cmd([]string{"wip", "that", "sub thing"}, expectLines(
+			"# 2020-07-24 WIP",
+			"1. that",
+			"   1. sub thing",
+		)),
+		cmd([]string{"done", "finished"}, expectLines(
+			"# 2020-07-24 Done",
+			"1. finished",
+		)),
+		cmd([]string{"today", "nope"}, errors.New(
+			`no item matched ["nope"], cannot add new items directly under today`,
+		)),
+		expectStream(expectLines(
+			"# 2020-07-24",
+			"",
+			"## TODO",
+			"- the other thing",
+			"- and then...",
+			"- a new thing",
+			"## WIP",
+			"- that",
+			"  - sub thing",
+			"## Done",
+			"- finished",
+			"# 2020-07-23",
+			"- this",
+			"# 2020-07-22",
+			"- different things",
+		)),
+
+		cmd([]string{"wip", "new thing"}, expectLines(
+			"Moved from 2020-07-24 TODO: a new thing",
+			"",
+			"# 2020-07-24 WIP",
+			"1. a new thing",
+		)),
+		cmd([]string{"done", "that", "sub"}, expectLines(
+			"Moved from 2020-07-24 WIP: sub thing",
+			"",
+			"# 2020-07-24 Done",
+			"1. that",
+			"   1. sub thing",
+		)),
+		cmd([]string{"done", "that", "another"}, expectLines(
+			"# 2020-07-24 Done",
+			"1. that",
+			"   1. another",
+		)),
+		expectStream(expectLines(
+			"# 2020-07-24",
+			"",
+			"## TODO",
+			"- the other thing",
+			"- and then...",
+			"## WIP",
+			"- that",
+			"- a new thing",
+			"## Done",
+			"- finished",
+			"- that",
+			"  - sub thing",
+			"  - another",
+			"# 2020-07-23",
+			"- this",
+			"# 2020-07-22",
+			"- different things",
+		)),
+
+		cmd([]string{"drop", "done", "that", "sub"}, expectLines(
+			"# Dropped from 2020-07-24 Done",
+			"1. that",
+			"   1. sub thing",
+		)),
+		cmd([]string{"drop", "done", "another"}, expectLines(
+			"# Dropped from 2020-07-24 Done",
+			"1. that",
+			"   1. another",
+		)),
+		cmd([]string{"drop", "wip", "nope"}, errors.New(
+			`no 2020-07-24 WIP item matched ["nope"]`,
+		)),
+		cmd([]string{"drop", "bogus"}, errors.New(
+			`unknown section "bogus", usage: socTest drop <todo|wip|done> <match...>`,
+		)),
+		expectStream(expectLines(
+			"# 2020-07-24",
+			"",
+			"## TODO",
+			"- the other thing",
+			"- and then...",
+			"## WIP",
+			"- that",
+			"- a new thing",
+			"## Done",
+			"- finished",
+			"# 2020-07-23",
+			"- this",
+			"# 2020-07-22",
+			"- different things",
+		)),
+
+		cmd([]string{"done", "thing"}, expectLines(
+			`ambiguous match for ["thing"], choose one of 2 candidate items:`,
+			"1. 2020-07-24 TODO › the other thing",
+			"2. 2020-07-24 WIP › a new thing",
+			"",
+			"reply with `socTest <number>` to choose",
+		)),
+		cmd([]string{"3"}, errors.New(
+			"invalid choice 3, expected 1-2",
+		)),
+		cmd([]string{"1"}, expectLines(
+			"Moved from 2020-07-24 TODO: the other thing",
+			"",
+			"# 2020-07-24 Done",
+			"1. the other thing",
+		)),
+		cmd([]string{"1"}, errors.New(
+			"no pending choice to reply to",
+		)),
+		cmd([]string{"done", "this"}, expectLines(
+			"# 2020-07-24 Done",
+			"1. this",
+		)),
+		cmd([]string{"drop", "done", "this"}, expectLines(
+			"# Dropped from 2020-07-24 Done",
+			"1. this",
+		)),
+		cmd([]string{"done", "-yesterday", "this"}, expectLines(
+			"Moved from 2020-07-23: this",
+			"",
+			"# 2020-07-24 Done",
+			"1. this",
+		)),
+		expectStream(expectLines(
+			"# 2020-07-24",
+			"",
+			"## TODO",
+			"- and then...",
+			"## WIP",
+			"- that",
+			"- a new thing",
+			"## Done",
+			"- finished",
+			"- the other thing",
+			"- this",
+			"# 2020-07-23",
+			"# 2020-07-22",
+			"- different things",
+		)),
+
+		cmd([]string{"note", ".", "remark"}, expectLines(
+			"# 2020-07-24 Done",
+			"1. this",
+			"   1. remark",
+		)),
+		cmd([]string{"wip", "."}, expectLines(
+			"Moved from 2020-07-24 Done: this",
+			"",
+			"# 2020-07-24 WIP",
+			"1. this",
+			"   1. remark",
+		)),
+		cmd([]string{"note", "nope", "remark"}, errors.New(
+			`no item matched ["nope"]`,
+		)),
+		expectStream(expectLines(
+			"# 2020-07-24",
+			"",
+			"## TODO",
+			"- and then...",
+			"## WIP",
+			"- that",
+			"- a new thing",
+			"- this",
+			"  - remark",
+			"## Done",
+			"- finished",
+			"- the other thing",
+			"# 2020-07-23",
+			"# 2020-07-22",
+			"- different things",
+		)),
+	)
+}
+
+func Test_ui_dashedItems(t *testing.T) {
+	runUITest(t,
+		time.Date(2020, 7, 24, 1, 2, 3, 0, time.UTC),
+
+		fakeStream("today",
+			"# 2020-07-24\n",
+			"\n",
+			"## TODO\n",
+			"\n",
+			"## WIP\n",
+			"\n",
+			"## Done\n",
+		),
+
+		// item text is not parsed as flags, once past any defined ones
+		cmd([]string{"todo", "-foo", "bar"}, expectLines(
+			"# 2020-07-24 TODO",
+			"1. -foo bar",
+		)),
+		cmd([]string{"todo", "---", "x"}, expectLines(
+			"# 2020-07-24 TODO",
+			"1. --- x",
+		)),
+		cmd([]string{"todo", "-raw"}, expectLines(
+			"# 2020-07-24",
+			"",
+			"## TODO",
+			"",
+			"- -foo bar",
+			"- --- x",
+		)),
+	)
+}
+
+func Test_ui_moveParents(t *testing.T) {
+	runUITest(t,
+		time.Date(2020, 7, 24, 1, 2, 3, 0, time.UTC),
+
+		fakeStream("nested items",
+			"# 2020-07-24\n",
+			"\n",
+			"## TODO\n",
+			"\n",
+			"- [scanio]\n",
+			"  - arena nil safety\n",
+			"- write docs\n",
+			"  - for outline\n",
+			"\n",
+			"## Done\n",
+		),
+
+		// an [area] left empty only grouped its items, and is pruned
+		cmd([]string{"done", "arena"}, expectLines(
+			"Moved from 2020-07-24 TODO: arena nil safety",
+		)),
+
+		// while a task remains, even once all its sub-items are done
+		cmd([]string{"done", "outline"}, expectLines(
+			"Moved from 2020-07-24 TODO: for outline",
+		)),
+
+		expectStream(expectLines(
+			"# 2020-07-24",
+			"",
+			"## TODO",
+			"",
+			"- write docs",
+			"",
+			"## Done",
+			"- [scanio]",
+			"  - arena nil safety",
+			"- write docs",
+			"  - for outline",
+		)),
+	)
+}
+
+func Test_ui_choose(t *testing.T) {
+	runUITest(t,
+		time.Date(2020, 7, 24, 1, 2, 3, 0, time.UTC),
+
+		fakeStream("duplicate items",
+			"# 2020-07-24\n",
+			"\n",
+			"## TODO\n",
+			"\n",
+			"- [dup]\n",
+			"  - first\n",
+			"- [dup]\n",
+			"  - second\n",
+			"- [dup]\n",
+			"  - third\n",
+			"\n",
+			"## WIP\n",
+			"\n",
+			"## Done\n",
+		),
+
+		cmd([]string{"drop", "todo", "dup"}, expectLines(
+			`ambiguous match for ["dup"], choose one of 3 candidate items:`,
+			"1. 2020-07-24 TODO › [dup]",
+			"2. 2020-07-24 TODO › [dup]",
+			"3. 2020-07-24 TODO › [dup]",
+			"",
+			"reply with `socTest <number>` to choose",
+		)),
+		cmd([]string{"2"}, expectLines(
+			"# Dropped from 2020-07-24 TODO",
+			"1. [dup]",
+			"   1. second",
+		)),
+
+		cmd([]string{"drop", "todo", "dup"}, expectLines(
+			`ambiguous match for ["dup"], choose one of 2 candidate items:`,
+		)),
+		cmd([]string{"done", "first"}, expectLines(
+			"Moved from 2020-07-24 TODO: first",
+		)),
+		cmd([]string{"1"}, errors.New(
+			`chosen "2020-07-24 TODO › [dup]" is no longer an option, the candidates have changed`,
+		)),
+		expectStream(expectLines(
+			"# 2020-07-24",
+			"",
+			"## TODO",
+			"",
+			"- [dup]",
+			"  - third",
+			"",
+			"## WIP",
+			"",
+			"## Done",
+			"- [dup]",
+			"  - first",
+		)),
+	)
+}
+
+func Test_ui_chooseAsOf(t *testing.T) {
+	runUITest(t,
+		time.Date(2020, 7, 24, 1, 2, 3, 0, time.UTC),
+
+		fakeStream("duplicate items on two days",
+			"# 2020-07-24\n",
+			"\n",
+			"## TODO\n",
+			"\n",
+			"## Done\n",
+			"\n",
+			"- dup\n",
+			"  - today first\n",
+			"- dup\n",
+			"  - today second\n",
+			"\n",
+			"# 2020-07-22\n",
+			"\n",
+			"## TODO\n",
+			"\n",
+			"## Done\n",
+			"\n",
+			"- dup\n",
+			"  - old first\n",
+			"- dup\n",
+			"  - old second\n",
+		),
+
+		asOf("2020-07-22"),
+		cmd([]string{"drop", "done", "dup"}, expectLines(
+			`ambiguous match for ["dup"], choose one of 2 candidate items:`,
+			"1. 2020-07-22 Done › dup",
+			"2. 2020-07-22 Done › dup",
+		)),
+
+		// the reply runs as of the prompt's date, not the reply's
+		asOf(""),
+		cmd([]string{"2"}, expectLines(
+			"# Dropped from 2020-07-22 Done",
+			"1. dup",
+			"   1. old second",
+		)),
+		cmd([]string{"today"}, expectLines(
+			"# 2020-07-24",
+			"1. TODO",
+			"2. Done",
+			"   1. dup",
+			"      1. today first",
+			"   2. dup",
+			"      1. today second",
+		)),
+	)
+}
+
+func Test_ui_choosePreview(t *testing.T) {
+	runUITest(t,
+		time.Date(2020, 7, 24, 1, 2, 3, 0, time.UTC),
+
+		fakeStream("duplicate items",
+			"# 2020-07-24\n",
+			"\n",
+			"## TODO\n",
+			"\n",
+			"- dup\n",
+			"  - first\n",
+			"- dup\n",
+			"  - second\n",
+			"\n",
+			"## Done\n",
+		),
+
+		asOf("tomorrow"),
+		cmd([]string{"drop", "todo", "dup"}, expectLines(
+			"Created Today by rolling 2020-07-24 forward",
+			"",
+			`ambiguous match for ["dup"], choose one of 2 candidate items:`,
+			"1. 2020-07-25 TODO › dup",
+			"2. 2020-07-25 TODO › dup",
+		)),
+
+		// the reply is previewed too, leaving the stream unchanged
+		asOf(""),
+		cmd([]string{"1"}, expectLines(
+			"Created Today by rolling 2020-07-24 forward",
+			"",
+			"# Dropped from 2020-07-25 TODO",
+			"1. dup",
+			"   1. first",
+		)),
+		expectStream(expectLines(
+			"# 2020-07-24",
+			"",
+			"## TODO",
+			"",
+			"- dup",
+			"  - first",
+			"- dup",
+			"  - second",
+			"",
+			"## Done",
+		)),
+	)
+}
+
+func Test_ui_aliases(t *testing.T) {
+	runUITest(t,
+		time.Date(2020, 7, 24, 1, 2, 3, 0, time.UTC),
+
+		fakeStream("aliased sections",
+			"# 2020-07-24\n",
+			"\n",
+			"## FIXME\n",
+			"- the bug\n",
+			"## Doing\n",
+			"- that\n",
+			"## Done\n",
+		),
+
+		// no aliases are recognized by default
+		cmd([]string{"bug", "another"}, expectLines(
+			`unrecognized command "bug"`,
+		)),
+
+		fakeConfig(`{"itemTypes": [
+			{"name": "TODO", "aliases": ["bug", "FIXME"]},
+			{"name": "WIP", "aliases": ["doing"]},
+			{"name": "Done", "aliases": ["finished"], "remains": true}
+		]}`),
+		cmd([]string{"bug", "another"}, expectLines(
+			"# 2020-07-24 FIXME",
+			"1. another",
+		)),
+		cmd([]string{"doing"}, expectLines(
+			"# 2020-07-24 Doing",
+			"1. that",
+		)),
+		cmd([]string{"finished", "that"}, expectLines(
+			"Moved from 2020-07-24 Doing: that",
+			"",
+			"# 2020-07-24 Done",
+			"1. that",
+		)),
+		expectStream(expectLines(
+			"# 2020-07-24",
+			"",
+			"## FIXME",
+			"- the bug",
+			"- another",
+			"## Doing",
+			"## Done",
+			"- that",
+		)),
+	)
+}
+
+func Test_ui_planned(t *testing.T) {
+	runUITest(t,
+		time.Date(2020, 7, 24, 1, 2, 3, 0, time.UTC),
+
+		fakeStream("planned items",
+			"# Future\n",
+			"\n",
+			"- 2020-07-24 renew cert\n",
+			"  see the wiki\n",
+			"- 2020-08-01 later thing\n",
+			"\n",
+			"# 2020-07-25\n",
+			"\n",
+			"- plan for saturday\n",
+			"\n",
+			"# 2020-07-24\n",
+			"\n",
+			"- weekend prep\n",
+			"  - buy stuff\n",
+			"\n",
+			"# 2020-07-22\n",
+			"\n",
+			"## TODO\n",
+			"\n",
+			"- old todo\n",
+			"\n",
+			"## WIP\n",
+			"\n",
+			"## Done\n",
+			"\n",
+			"- did it\n",
+		),
+
+		cmd([]string{"today"}, expectLines(
+			"Created Today by rolling 2020-07-22 forward",
+			"Pulled from 2020-07-24: renew cert",
+			"Pulled from 2020-07-24: weekend prep",
+			"",
+			"# 2020-07-24",
+			"1. TODO",
+			"   1. old todo",
+			"   2. renew cert see the wiki",
+			"      1. planned for 2020-07-24",
+			"   3. weekend prep",
+			"      1. buy stuff",
+			"      2. planned for 2020-07-24",
+			"2. WIP",
+			"3. Done",
+		)),
+		expectStream(expectLines(
+			"# Future",
+			"",
+			"- 2020-08-01 later thing",
+			"",
+			"# 2020-07-25",
+			"",
+			"- plan for saturday",
+			"",
+			"# 2020-07-24",
+			"",
+			"## TODO",
+			"",
+			"- old todo",
+			"- renew cert",
+			"  see the wiki",
+			"  - planned for 2020-07-24",
+			"- weekend prep",
+			"  - buy stuff",
+			"  - planned for 2020-07-24",
+			"",
+			"## WIP",
+			"",
+			"## Done",
+			"",
+			"# 2020-07-22",
+			"",
+			"- did it",
+		)),
+
+		24*time.Hour,
+		cmd([]string{"today"}, expectLines(
+			"Created Today by rolling 2020-07-24 forward",
+			"Pulled from 2020-07-25: plan for saturday",
+			"",
+			"# 2020-07-25",
+			"1. TODO",
+			"   1. old todo",
+			"   2. renew cert see the wiki",
+			"      1. planned for 2020-07-24",
+			"   3. weekend prep",
+			"      1. buy stuff",
+			"      2. planned for 2020-07-24",
+			"   4. plan for saturday",
+			"      1. planned for 2020-07-25",
+			"2. WIP",
+			"3. Done",
+		)),
+	)
+}
+
+func Test_ui_defer(t *testing.T) {
+	runUITest(t,
+		time.Date(2020, 7, 24, 1, 2, 3, 0, time.UTC),
+
+		fakeStream("deferable items",
+			"# 2020-08-01\n",
+			"\n",
+			"- august plan\n",
+			"\n",
+			"# 2020-07-24\n",
+			"\n",
+			"## TODO\n",
+			"\n",
+			"- alpha\n",
+			"- beta\n",
+			"- gamma\n",
+			"\n",
+			"## WIP\n",
+			"\n",
+			"## Done\n",
+			"\n",
+			"- did\n",
+		),
+
+		cmd([]string{"defer", "alpha", "2020-08"}, expectLines(
+			"# Deferred from 2020-07-24 TODO until 2020-08",
+			"1. alpha",
+		)),
+		cmd([]string{"defer", "beta", "2020-08-01"}, expectLines(
+			"# Deferred from 2020-07-24 TODO until 2020-08-01",
+			"1. beta",
+		)),
+		cmd([]string{"defer", "gamma", "+6d"}, expectLines(
+			"# Deferred from 2020-07-24 TODO until 2020-07-30",
+			"1. gamma",
+		)),
+		cmd([]string{"defer", "nope", "2020-09"}, errors.New(
+			`no 2020-07-24 TODO item matched ["nope"]`,
+		)),
+		cmd([]string{"defer", "did", "2020-07"}, errors.New(
+			"cannot defer until 2020-07, must be after 2020-07-24",
+		)),
+		cmd([]string{"defer", "did", "soon"}, errors.New(
+			`invalid date "soon", expected an ISO date like 2006-01-02, or a relative one like tomorrow, +3d, or next monday, usage: socTest defer <match...> <date>`,
+		)),
+		cmd([]string{"defer", "did", "2020-08-01T12:00"}, errors.New(
+			"cannot defer until 2020-08-01T12:00Z, must be a year, month, week, or day",
+		)),
+		cmd([]string{"defer", "did", "2020-09-31"}, errors.New(
+			`invalid date "2020-09-31", invalid day 31, 2020-09 has 30 days, usage: socTest defer <match...> <date>`,
+		)),
+		expectStream(expectLines(
+			"# 2020-08-01",
+			"",
+			"- august plan",
+			"- beta",
+			"",
+			"# 2020-08",
+			"",
+			"- alpha",
+			"",
+			"# 2020-07-30",
+			"",
+			"- gamma",
+			"",
+			"# 2020-07-24",
+			"",
+			"## TODO",
+			"",
+			"## WIP",
+			"",
+			"## Done",
+			"",
+			"- did",
+		)),
+
+		6*24*time.Hour,
+		cmd([]string{"todo"}, expectLines(
+			"Created Today by rolling 2020-07-24 forward",
+			"Pulled from 2020-07-30: gamma",
+			"",
+			"# 2020-07-30 TODO",
+			"1. gamma",
+			"   1. planned for 2020-07-30",
+		)),
+	)
+}
+
+func Test_ui_weeks(t *testing.T) {
+	runUITest(t,
+		time.Date(2020, 7, 26, 1, 2, 3, 0, time.UTC),
+
+		fakeStream("weekly plans",
+			"# 2020-W31\n",
+			"\n",
+			"- weekly goal\n",
+			"\n",
+			"## 2020-07-29\n",
+			"\n",
+			"- midweek thing\n",
+			"\n",
+			"# 2020-07-26\n",
+			"\n",
+			"## TODO\n",
+			"\n",
+			"- thing\n",
+			"\n",
+			"## WIP\n",
+			"\n",
+			"## Done\n",
+		),
+
+		cmd([]string{"list"}, expectLines(
+			"# 2020-W31",
+			"1. weekly goal",
+			"",
+			"# 2020-07-29",
+			"1. midweek thing",
+			"",
+			"# 2020-07-26",
+			"1. TODO",
+			"2. WIP",
+			"3. Done",
+		)),
+
+		24*time.Hour,
+		cmd([]string{"todo"}, expectLines(
+			"Created Today by rolling 2020-07-26 forward",
+			"Pulled from 2020-W31: weekly goal",
+			"",
+			"# 2020-07-27 TODO",
+			"1. thing",
+			"2. weekly goal",
+			"   1. planned for 2020-W31",
+		)),
+	)
+}
+
+func Test_ui_plannedPast(t *testing.T) {
+	runUITest(t,
+		time.Date(2020, 7, 24, 1, 2, 3, 0, time.UTC),
+
+		fakeStream("a day logged out of order",
+			"# 2020-07-20\n",
+			"\n",
+			"- logged late\n",
+			"\n",
+			"# 2020-07-22\n",
+			"\n",
+			"## TODO\n",
+			"\n",
+			"- old todo\n",
+			"\n",
+			"## WIP\n",
+			"\n",
+			"## Done\n",
+			"\n",
+			"- did it\n",
+		),
+
+		cmd([]string{"today"}, expectLines(
+			"Created Today by rolling 2020-07-22 forward",
+			"",
+			"# 2020-07-24",
+			"1. TODO",
+			"   1. old todo",
+			"2. WIP",
+			"3. Done",
+		)),
+		expectStream(expectLines(
+			"# 2020-07-20",
+			"",
+			"- logged late",
+			"",
+			"# 2020-07-24",
+			"",
+			"## TODO",
+			"",
+			"- old todo",
+			"",
+			"## WIP",
+			"",
+			"## Done",
+			"",
+			"# 2020-07-22",
+			"",
+			"- did it",
+		)),
+	)
+}
+
+func Test_ui_config(t *testing.T) {
+	runUITest(t,
+		time.Date(2020, 7, 24, 1, 2, 3, 0, time.UTC),
+
+		fakeConfig(`{
+			"itemTypes": [
+				{"name": "Next", "aliases": ["todo"]},
+				{"name": "Finished", "remains": true}
+			],
+			"dayFormat": "2006-01-02 Monday",
+			"aliases": {"fin": "finished"},
+			"defaultCommand": "today"
+		}`),
+
+		cmd(nil, expectLines(
+			"Created new Today section at top of stream",
+			"",
+			"# 2020-07-24 Friday",
+			"1. Next",
+			"2. Finished",
+		)),
+		cmd([]string{"todo", "a thing"}, expectLines(
+			"# 2020-07-24 Friday Next",
+			"1. a thing",
+		)),
+		cmd([]string{"fin", "thing"}, expectLines(
+			"Moved from 2020-07-24 Friday Next: a thing",
+			"",
+			"# 2020-07-24 Friday Finished",
+			"1. a thing",
+		)),
+		cmd([]string{"wip"}, expectLines(
+			`unrecognized command "wip"`,
+		)),
+		expectStream(expectLines(
+			"# 2020-07-24 Friday",
+			"",
+			"## Next",
+			"",
+			"## Finished",
+			"",
+			"- a thing",
+		)),
+
+		24*time.Hour,
+		cmd(nil, expectLines(
+			"Created Today by rolling 2020-07-24 Friday forward",
+			"",
+			"# 2020-07-25 Saturday",
+			"1. Next",
+			"2. Finished",
+		)),
+
+		fakeConfig(`{"dayFormat": "Monday 2006-01-02"}`),
+		cmd(nil, errors.New(
+			`invalid day format "Monday 2006-01-02", must start with an ISO date like 2006-01-02`,
+		)),
+	)
+}
+
+func Test_ui_dayStart(t *testing.T) {
+	if _, err := time.LoadLocation("America/New_York"); err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+	runUITest(t,
+		// 01:00 in New York, still the 23rd
+		time.Date(2020, 7, 24, 5, 0, 0, 0, time.UTC),
+
+		fakeStream("late night",
+			"# 2020-07-23\n",
+			"\n",
+			"## TODO\n",
+			"\n",
+			"- ship it\n",
+			"\n",
+			"## Done\n",
+		),
+		fakeConfig(`{"timeZone": "America/New_York", "dayStart": "04:00"}`),
+
+		cmd([]string{"done", "ship"}, expectLines(
+			"Moved from 2020-07-23 TODO: ship it",
+			"",
+			"# 2020-07-23 Done",
+			"1. ship it",
+		)),
+
+		// 03:59 is still the 23rd
+		2*time.Hour+59*time.Minute,
+		cmd([]string{"today"}, expectLines(
+			"# 2020-07-23",
+			"1. TODO",
+			"2. Done",
+			"   1. ship it",
+		)),
+
+		// 04:00 starts the 24th
+		time.Minute,
+		cmd([]string{"today"}, expectLines(
+			"Created Today by rolling 2020-07-23 forward",
+			"",
+			"# 2020-07-24",
+			"1. TODO",
+			"2. WIP",
+			"3. Done",
+		)),
+
+		fakeConfig(`{"timeZone": "Mars/Olympus_Mons"}`),
+		cmd([]string{"today"}, errors.New(
+			`invalid time zone "Mars/Olympus_Mons": unknown time zone Mars/Olympus_Mons`,
+		)),
+
+		fakeConfig(`{"dayStart": "4am"}`),
+		cmd([]string{"today"}, errors.New(
+			`invalid day start "4am", must be a time of day like 04:00`,
+		)),
+	)
+}
+
+func Test_ui_backfill(t *testing.T) {
+	runUITest(t,
+		time.Date(2020, 7, 22, 1, 2, 3, 0, time.UTC),
+
+		cmd([]string{"done", "old thing"}, expectLines(
+			"Created new Today section at top of stream",
+		)),
+		48*time.Hour,
+		cmd([]string{"todo", "current thing"}, expectLines(
+			"Created Today by rolling 2020-07-22 forward",
+		)),
+
+		// rollover elided the past day's Done header, leaving its items
+		// directly under the day heading
+		asOf("2020-07-22"),
+		cmd([]string{"done", "backfilled"}, expectLines(
+			"# 2020-07-22 Done",
+			"1. backfilled",
+		)),
+		cmd([]string{"done"}, expectLines(
+			"# 2020-07-22 Done",
+			"1. old thing",
+			"2. backfilled",
+		)),
+		cmd([]string{"done", "-raw"}, expectLines(
+			"# 2020-07-22",
+			"",
+			"- old thing",
+			"- backfilled",
+		)),
+		expectStream(expectLines(
+			"# 2020-07-24",
+			"",
+			"## TODO",
+			"",
+			"- current thing",
+			"## WIP",
+			"",
+			"## Done",
+			"",
+			"# 2020-07-22",
+			"",
+			"- old thing",
+			"- backfilled",
+		)),
+	)
+}
+
+func Test_ui_lastZone(t *testing.T) {
+	// far from any likely time.Local, where the last item date is unmarshaled
+	if _, err := time.LoadLocation("Asia/Tokyo"); err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+	runUITest(t,
+		// 12:00 in Tokyo
+		time.Date(2020, 7, 23, 3, 0, 0, 0, time.UTC),
+
+		fakeStream("zoned",
+			"# 2020-07-23\n",
+			"\n",
+			"## TODO\n",
+			"\n",
+			"- thing\n",
+			"\n",
+			"## WIP\n",
+			"\n",
+			"## Done\n",
+		),
+		fakeConfig(`{"timeZone": "Asia/Tokyo"}`),
+
+		cmd([]string{"done", "thing"}, expectLines(
+			"Moved from 2020-07-23 TODO: thing",
+		)),
+
+		24*time.Hour,
+		cmd([]string{"note", ".", "remark"}, expectLines(
+			"Created Today by rolling 2020-07-23 forward",
+			"",
+			"# 2020-07-23",
+			"1. thing",
+			"   1. remark",
+		)),
+	)
+}
+
+func Test_ui_asOf(t *testing.T) {
+	runUITest(t,
+		time.Date(2020, 7, 24, 1, 2, 3, 0, time.UTC),
+
+		fakeStream("lived days",
+			"# 2020-07-24\n",
+			"\n",
+			"## TODO\n",
+			"\n",
+			"- current thing\n",
+			"\n",
+			"## WIP\n",
+			"\n",
+			"## Done\n",
+			"\n",
+			"- current done\n",
+			"\n",
+			"# 2020-07-22\n",
+			"\n",
+			"## Done\n",
+			"\n",
+			"- old thing\n",
+		),
+
+		asOf("2020-07-22"),
+		cmd([]string{"done", "forgotten thing"}, expectLines(
+			"# 2020-07-22 Done",
+			"1. forgotten thing",
+		)),
+
+		asOf("yesterday"),
+		cmd([]string{"today"}, errors.New(
+			"cannot roll the stream back to 2020-07-23, it already has 2020-07-24",
+		)),
+
+		asOf("next month"),
+		cmd([]string{"today"}, errors.New(
+			"invalid -date option 2020-08, must be a day",
+		)),
+		asOf("soon"),
+		cmd([]string{"today"}, errors.New(
+			`invalid -date option: invalid date "soon", expected an ISO date like 2006-01-02, or a relative one like tomorrow, +3d, or next monday`,
+		)),
+
+		asOf("tomorrow"),
+		cmd([]string{"todo"}, expectLines(
+			"Created Today by rolling 2020-07-24 forward",
+			"",
+			"# 2020-07-25 TODO",
+			"1. current thing",
+		)),
+
+		// tomorrow was only previewed, so the stream is still usable today
+		asOf(""),
+		cmd([]string{"today"}, expectLines(
+			"# 2020-07-24",
+		)),
+		expectStream(expectLines(
+			"# 2020-07-24",
+			"",
+			"## TODO",
+			"",
+			"- current thing",
+			"",
+			"## WIP",
+			"",
+			"## Done",
+			"",
+			"- current done",
+			"",
+			"# 2020-07-22",
+			"",
+			"## Done",
+			"",
+			"- old thing",
+			"- forgotten thing",
+		)),
+	)
+}
+
+func Test_ui_show(t *testing.T) {
+	runUITest(t,
+		time.Date(2020, 9, 4, 1, 2, 3, 0, time.UTC),
+
+		cmd([]string{"show", "today"}, errors.New(
+			"no days found within 2020-09-04, stream is empty",
+		)),
+
+		fakeStream("history",
+			"# 2020-09-04\n",
+			"\n",
+			"## TODO\n",
+			"\n",
+			"- next thing\n",
+			"\n",
+			"## Done\n",
+			"\n",
+			"- shipped it\n",
+			"\n",
+			"# 2020-09-03\n",
+			"\n",
+			"- [scanio]\n",
+			"  - arena nil safety\n",
+			"\n",
+			"# 2020-09-01\n",
+			"\n",
+			"- started\n",
+			"\n",
+			"# 2020-08-31\n",
+			"\n",
+			"- august thing\n",
+		),
+
+		cmd([]string{"show", "yesterday"}, expectLines(
+			"# 2020-09-03",
+			"1. [scanio]",
+			"   1. arena nil safety",
+		)),
+		cmd([]string{"show", "2020-09"}, expectLines(
+			"# 2020-09-04",
+			"1. TODO",
+			"   1. next thing",
+			"2. Done",
+			"   1. shipped it",
+			"",
+			"# 2020-09-03",
+			"1. [scanio]",
+			"   1. arena nil safety",
+			"",
+			"# 2020-09-01",
+			"1. started",
+		)),
+		cmd([]string{"show", "2020-08-31..2020-09-01"}, expectLines(
+			"# 2020-09-01",
+			"1. started",
+			"",
+			"# 2020-08-31",
+			"1. august thing",
+		)),
+		cmd([]string{"show", "-raw", "2020-08-31"}, expectLines(
+			"# 2020-08-31",
+			"",
+			"- august thing",
+		)),
+		cmd([]string{"show", "2020-09-02"}, errors.New(
+			"no days found within 2020-09-02",
+		)),
+		cmd([]string{"show", "today..yesterday"}, errors.New(
+			`invalid range "today..yesterday", 2020-09-04 is not before 2020-09-03, usage: socTest show [-raw] <date|range>`,
+		)),
+		cmd([]string{"show"}, errors.New(
+			"usage: socTest show [-raw] <date|range>",
+		)),
+	)
+}
+
+func Test_ui_raw(t *testing.T) {
+	runUITest(t,
+		time.Date(2020, 7, 24, 1, 2, 3, 0, time.UTC),
+
+		fakeStream("markdown",
+			"# 2020-07-24\n",
+			"\n",
+			"## TODO\n",
+			"\n",
+			"- a *thing*\n",
+			"  - with [detail](http://example.com)\n",
+			"\n",
+			"## WIP\n",
+			"\n",
+			"## Done\n",
+			"\n",
+			"- did `it`\n",
+			"\n",
+			"# 2020-07-23\n",
+			"\n",
+			"- old\n",
+		),
+
+		cmd([]string{"today", "-raw"}, expectLines(
+			"# 2020-07-24",
+			"",
+			"## TODO",
+			"",
+			"- a *thing*",
+			"  - with [detail](http://example.com)",
+			"",
+			"## WIP",
+			"",
+			"## Done",
+			"",
+			"- did `it`",
+		)),
+		cmd([]string{"todo", "-raw"}, expectLines(
+			"# 2020-07-24",
+			"",
+			"## TODO",
+			"",
+			"- a *thing*",
+			"  - with [detail](http://example.com)",
+		)),
+		cmd([]string{"done", "-raw", "more"}, expectLines(
+			"# 2020-07-24",
+			"",
+			"## Done",
+			"",
+			"- did `it`",
+			"- more",
+		)),
+		cmd([]string{"list", "-raw"}, expectLines(
+			"# 2020-07-24",
+			"",
+			"## TODO",
+			"",
+			"- a *thing*",
+			"  - with [detail](http://example.com)",
+			"",
+			"## WIP",
+			"",
+			"## Done",
+			"",
+			"- did `it`",
+			"- more",
+			"",
+			"# 2020-07-23",
+			"",
+			"- old",
+		)),
+	)
+}
+
+func Test_ui_query(t *testing.T) {
+	runUITest(t,
+		time.Date(2020, 9, 4, 1, 2, 3, 0, time.UTC),
+
+		fakeStream("history",
+			"# 2020-09-04\n",
+			"\n",
+			"## TODO\n",
+			"\n",
+			"- tag things #scandown\n",
+			"\n",
+			"## Done\n",
+			"\n",
+			"- fix parser\n",
+			"\n",
+			"# 2020-09-03\n",
+			"\n",
+			"- [scanio]\n",
+			"  - arena nil safety\n",
+			"  - parser cleanup #scandown\n",
+			"\n",
+			"# 2020-08-31\n",
+			"\n",
+			"- august thing\n",
+		),
+
+		cmd([]string{"list", "since:2020-09", "level:2"}, expectLines(
+			"# 2020-09-04",
+			"1. TODO",
+			"   1. tag things #scandown",
+			"2. Done",
+			"   1. fix parser",
+			"",
+			"# 2020-09-03",
+			"1. [scanio]",
+			"   1. arena nil safety",
+			"   2. parser cleanup #scandown",
+		)),
+		cmd([]string{"list", "until:2020-09-03", "level:1"}, expectLines(
+			"# 2020-09-03",
+			"1. [scanio]",
+			"",
+			"# 2020-08-31",
+			"1. august thing",
+		)),
+		cmd([]string{"list", "text:/^parser/", "OR", "tag:scandown"}, expectLines(
+			"# 2020-09-04",
+			"1. TODO",
+			"   1. tag things #scandown",
+			"",
+			"# 2020-09-03",
+			"1. [scanio]",
+			"   1. parser cleanup #scandown",
+		)),
+		cmd([]string{"list", "parser", "-tag:scandown"}, expectLines(
+			"# 2020-09-04",
+			"1. Done",
+			"   1. fix parser",
+		)),
+		cmd([]string{"list", "section:done", "NOT", "level:2"}, expectLines(
+			"# 2020-09-04",
+			"1. Done",
+			"",
+			"# 2020-09-03",
+			"1. [scanio]",
+			"",
+			"# 2020-08-31",
+			"1. august thing",
+		)),
+		cmd([]string{"list", "-tag:scandown", "-level:1"}, expectLines(
+			"# 2020-09-04",
+			"1. Done",
+			"   1. fix parser",
+			"",
+			"# 2020-09-03",
+			"1. [scanio]",
+			"   1. arena nil safety",
+		)),
+		cmd([]string{"list", "-raw", "-section:todo", "tag:scandown"}, expectLines(
+			"# 2020-09-03",
+			"",
+			"- [scanio]",
+			"  - arena nil safety",
+			"  - parser cleanup #scandown",
+		)),
+		cmd([]string{"list", "section:todo"}, expectLines(
+			"# 2020-09-04",
+			"1. TODO",
+			"   1. tag things #scandown",
+		)),
+
+		cmd([]string{"list", "OR", "parser"}, errors.New(
+			"OR must be between terms, usage: socTest list [-raw] ["+queryHelp+"]",
+		)),
+		cmd([]string{"list", "parser", "NOT"}, errors.New(
+			"NOT must be followed by a term, usage: socTest list [-raw] ["+queryHelp+"]",
+		)),
+		cmd([]string{"list", "level:0"}, errors.New(
+			`invalid query term "level:0": level must be a positive number, usage: socTest list [-raw] [`+queryHelp+"]",
+		)),
+		cmd([]string{"list", "section:bogus"}, errors.New(
+			`invalid query term "section:bogus": unknown section, expected one of todo, wip, done, usage: socTest list [-raw] [`+queryHelp+"]",
+		)),
+		cmd([]string{"list", "when:now"}, errors.New(
+			`invalid query term "when:now": unknown key "when", expected `+queryHelp+", usage: socTest list [-raw] ["+queryHelp+"]",
+		)),
+	)
+}
+
+func Test_ui_search(t *testing.T) {
+	runUITest(t,
+		time.Date(2020, 9, 4, 1, 2, 3, 0, time.UTC),
+
+		cmd([]string{"search", "arena"}, errors.New(
+			`no matches for "arena", stream is empty`,
+		)),
+
+		fakeStream("history",
+			"# 2020-09-04\n",
+			"\n",
+			"## Done\n",
+			"\n",
+			"- fix parser\n",
+			"\n",
+			"  a body mentioning the arena\n",
+			"- 12:00 lunch\n",
+			"\n",
+			"# 2020-09-03\n",
+			"\n",
+			"- [scanio]\n",
+			"  - arena nil safety\n",
+			"  - parser cleanup\n",
+			"\n",
+			"# 2020-08-31\n",
+			"\n",
+			"- august thing\n",
+		),
+
+		cmd([]string{"search", "arena"}, expectLines(
+			"2020-09-04 › Done › fix parser",
+			"2020-09-03 › [scanio] › arena nil safety",
+		)),
+		cmd([]string{"search", "lunch|^august"}, expectLines(
+			"2020-09-04 › Done › lunch",
+			"2020-08-31 › august thing",
+		)),
+		cmd([]string{"search", "nothing"}, errors.New(
+			`no matches for "nothing"`,
+		)),
+		cmd([]string{"search", "(["}, errors.New(
+			"invalid search pattern: error parsing regexp: missing closing ]: `[`, usage: socTest search [-tag name] <pattern>",
+		)),
+		cmd([]string{"search"}, errors.New(
+			"usage: socTest search [-tag name] <pattern>",
+		)),
+	)
+}
+
+func Test_ui_tags(t *testing.T) {
+	runUITest(t,
+		time.Date(2020, 9, 4, 1, 2, 3, 0, time.UTC),
+
+		cmd([]string{"tags"}, errors.New(
+			"no tags found, stream is empty",
+		)),
+		cmd([]string{"tag", "food"}, errors.New(
+			"no items tagged #food, stream is empty",
+		)),
+
+		fakeStream("history",
+			"# 2020-09-04\n",
+			"\n",
+			"## TODO\n",
+			"\n",
+			"- tag things #scandown #Scandown\n",
+			"\n",
+			"## Done\n",
+			"\n",
+			"- fix parser #soc/ui, see #123\n",
+			"- lunch #food\n",
+			"\n",
+			"# 2020-09-03\n",
+			"\n",
+			"- [scanio] #scandown\n",
+			"  - arena nil safety\n",
+			"  - parser cleanup (#scandown)\n",
+			"  - not a#tag\n",
+			"\n",
+			"# 2020-08-31\n",
+			"\n",
+			"- august thing #food\n",
+		),
+
+		cmd([]string{"tags"}, expectLines(
+			"#scandown 3 items, last seen 2020-09-04",
+			"#food 2 items, last seen 2020-09-04",
+			"#soc/ui 1 item, last seen 2020-09-04",
+		)),
+		cmd([]string{"tag", "scandown"}, expectLines(
+			"2020-09-04 › TODO › tag things #scandown #Scandown",
+			"2020-09-03 › [scanio] #scandown",
+			"2020-09-03 › [scanio] #scandown › parser cleanup (#scandown)",
+		)),
+		cmd([]string{"tag", "#food"}, expectLines(
+			"2020-09-04 › Done › lunch #food",
+			"2020-08-31 › august thing #food",
+		)),
+		cmd([]string{"tag", "123"}, errors.New(
+			"no items tagged #123",
+		)),
+		cmd([]string{"tag"}, errors.New(
+			"usage: socTest tag <name>",
+		)),
+
+		cmd([]string{"search", "-tag", "food", "^aug"}, expectLines(
+			"2020-08-31 › august thing #food",
+		)),
+		cmd([]string{"search", "-tag", "food", "dinner"}, errors.New(
+			`no matches for #food "dinner"`,
+		)),
+		cmd([]string{"list", "#food", "OR", "tag:soc/ui"}, expectLines(
+			"# 2020-09-04",
+			"1. Done",
+			"   1. fix parser #soc/ui, see #123",
+			"   2. lunch #food",
+			"",
+			"# 2020-08-31",
+			"1. august thing #food",
+		)),
+	)
+}
+
+func Test_ui_areas(t *testing.T) {
+	runUITest(t,
+		time.Date(2020, 9, 4, 1, 2, 3, 0, time.UTC),
+
+		cmd([]string{"areas"}, errors.New(
+			"no areas found, stream is empty",
+		)),
+		cmd([]string{"area", "scanio"}, errors.New(
+			"no items found within [scanio], stream is empty",
+		)),
+
+		fakeStream("history",
+			"# 2020-09-04\n",
+			"\n",
+			"## TODO\n",
+			"\n",
+			"- [cmd/soc]\n",
+			"  - area command\n",
+			"\n",
+			"## Done\n",
+			"\n",
+			"- [scanio]\n",
+			"  - arena nil safety\n",
+			"- [ ] not an area, nor is [this](http://example.com)\n",
+			"\n",
+			"# 2020-09-03\n",
+			"\n",
+			"- [scanio]\n",
+			"  - splitter [abstraction]\n",
+			"- [cmd/soc]\n",
+			"  - show command\n",
+			"\n",
+			"# 2020-09-01\n",
+			"\n",
+			"- [Scanio] things\n",
+		),
+
+		cmd([]string{"areas"}, expectLines(
+			"[cmd/soc] last active 2020-09-04, on 2 days",
+			"[scanio] last active 2020-09-04, on 3 days",
+			"[abstraction] last active 2020-09-03, on 1 day",
+		)),
+		cmd([]string{"area", "scanio"}, expectLines(
+			"# 2020-09-04",
+			"1. Done",
+			"   1. [scanio]",
+			"      1. arena nil safety",
+			"",
+			"# 2020-09-03",
+			"1. [scanio]",
+			"   1. splitter [abstraction]",
+			"",
+			"# 2020-09-01",
+			"1. [Scanio] things",
+		)),
+		cmd([]string{"area", "[cmd/soc]"}, expectLines(
+			"# 2020-09-03",
+			"1. [cmd/soc]",
+			"   1. show command",
+		)),
+		cmd([]string{"area", "this"}, errors.New(
+			"no Done items found within [this]",
+		)),
+		cmd([]string{"area"}, errors.New(
+			"usage: socTest area <name>",
+		)),
+	)
+}
+
+func Test_ui_report(t *testing.T) {
+	runUITest(t,
+		time.Date(2020, 9, 4, 1, 2, 3, 0, time.UTC),
+
+		cmd([]string{"report", "this-week"}, errors.New(
+			"no Done items found within 2020-W36, stream is empty",
+		)),
+
+		fakeStream("history",
+			"# 2020-09-04\n",
+			"\n",
+			"## TODO\n",
+			"\n",
+			"- [cmd/soc]\n",
+			"  - report command\n",
+			"\n",
+			"## Done\n",
+			"\n",
+			"- [scanio]\n",
+			"  - arena nil safety\n",
+			"- fix [scandown] block bug\n",
+			"- shipped it\n",
+			"\n",
+			"# 2020-09-03\n",
+			"\n",
+			"- [scanio]\n",
+			"  - splitter\n",
+			"  - Arena nil safety\n",
+			"- [cmd/soc]\n",
+			"  - show command\n",
+			"\n",
+			"# 2020-08-28\n",
+			"\n",
+			"- august thing\n",
+		),
+
+		cmd([]string{"report", "this-week"}, expectLines(
+			"# Done 2020-W36",
+			"",
+			"- [scanio]",
+			"  - arena nil safety",
+			"  - splitter",
+			"- [scandown]",
+			"  - fix [scandown] block bug",
+			"- shipped it",
+			"- [cmd/soc]",
+			"  - show command",
+		)),
+		cmd([]string{"report", "2020-08"}, expectLines(
+			"# Done 2020-08",
+			"",
+			"- august thing",
+		)),
+		cmd([]string{"report", "2020-09-05..2020-09-07"}, errors.New(
+			"no Done items found within 2020-09-05..2020-09-07",
+		)),
+		cmd([]string{"report", "today..yesterday"}, errors.New(
+			`invalid range "today..yesterday", 2020-09-04 is not before 2020-09-03, usage: socTest report <date|range>`,
+		)),
+		cmd([]string{"report"}, errors.New(
+			"usage: socTest report <date|range>",
+		)),
 	)
 }
 
+func Test_ui_repl(t *testing.T) {
+	var ms memStore
+	ms.set(strings.Join([]string{
+		"# 2020-07-24",
+		"",
+		"## TODO",
+		"- a thing",
+		"- another thing",
+		"",
+	}, "\n"))
+
+	var u ui
+	u.args = []string{"socTest"}
+	u.store = &ms
+	now := time.Date(2020, 7, 24, 1, 2, 3, 0, time.UTC)
+
+	var out bytes.Buffer
+	ed := lineedit.New(&replScript{
+		"todo\n",
+		"\n",
+		"bogus\n",
+		func() {
+			words := []string{`"a thing"`, `"another thing"`}
+			start, cands := u.complete("done a")
+			assert.Equal(t, 5, start, "expected item start")
+			assert.Equal(t, words, cands, "expected item completions")
+			start, cands = u.complete(`done "an`)
+			assert.Equal(t, 5, start, "expected quoted item start")
+			assert.Equal(t, words[1:], cands, "expected quoted item completions")
+			start, cands = u.complete("wi")
+			assert.Equal(t, 0, start, "expected command start")
+			assert.Equal(t, []string{"wip"}, cands, "expected command completions")
+
+			ms.set(strings.Join([]string{
+				"# 2020-07-24",
+				"",
+				"## TODO",
+				"- changed on disk",
+				"",
+			}, "\n"))
+		},
+		"todo\n",
+	}, ioutil.Discard)
+	require.NoError(t, u.repl(ed, &out, func() time.Time { return now }))
+
+	expectLines(
+		"# 2020-07-24 TODO",
+		"1. a thing",
+		"2. another thing",
+		`unrecognized command "bogus"`,
+		"# 2020-07-24 TODO",
+		"1. changed on disk",
+	).expect(t, out.String())
+}
+
+// replScript is a reader of repl input lines, calling any interleaved
+// functions once all prior lines have been read.
+type replScript []interface{}
+
+func (rs *replScript) Read(p []byte) (int, error) {
+	for len(*rs) > 0 {
+		step := (*rs)[0]
+		*rs = (*rs)[1:]
+		switch v := step.(type) {
+		case func():
+			v()
+		case string:
+			return copy(p, v), nil
+		}
+	}
+	return 0, io.EOF
+}
+
+func fakeConfig(content string) uiTestStep {
+	return named{"fake config", true, withConfig(content)}
+}
+
+type withConfig string
+
+func (wc withConfig) run(t *uiTestContext) {
+	if t.store == nil {
+		t.store = &memStore{}
+	}
+	t.store.sibling(configFileName).(*memStore).set(string(wc))
+}
+
+func asOf(date string) uiTestStep {
+	return named{fmt.Sprintf("as of %q", date), true, withDate(date)}
+}
+
+type withDate string
+
+func (wd withDate) run(t *uiTestContext) { t.asOf = string(wd) }
+
 func fakeStream(name string, parts ...string) uiTestStep {
 	var content string
 	for _, part := range parts {
@@ -2,6 +2,7 @@ package isotime
 
 import (
 	"bytes"
+	"fmt"
 	"time"
 	"unicode"
 )
@@ -10,22 +11,30 @@ import (
 type TimeGrain uint
 
 // TimeGrain constants, from unset zero, down to second.
+//
+// An ISO week does not nest within a month, but is finer than one, and can
+// be refined into a day of the week.
 const (
 	TimeGrainNone TimeGrain = iota
 	TimeGrainYear
 	TimeGrainMonth
+	TimeGrainWeek
 	TimeGrainDay
 	TimeGrainHour
 	TimeGrainMinute
 	TimeGrainSecond
 )
 
-// GrainedTime is a variably grained ISO time range: a year, month, day, hour,
-// minute, or second. Its zero value has TimeGrainNone.
+// GrainedTime is a variably grained ISO time range: a year, month, week,
+// day, hour, minute, or second. Its zero value has TimeGrainNone.
+//
+// A week grained time's year is its ISO week-numbering year, which may differ
+// from the calendar year of its first or last days.
 type GrainedTime struct {
 	grain  TimeGrain
 	year   int
 	month  time.Month
+	week   int
 	day    int
 	hour   int
 	minute int
@@ -45,15 +54,28 @@ func (t GrainedTime) Year() int {
 	return 0
 }
 
-// Day returns the receiver's month component if it's at least TimeGrainMonth,
-// or zero otherwise.
+// Month returns the receiver's month component if it's at least
+// TimeGrainMonth, but not TimeGrainWeek, or zero otherwise.
 func (t GrainedTime) Month() time.Month {
-	if t.grain >= TimeGrainMonth {
+	if t.grain >= TimeGrainMonth && t.grain != TimeGrainWeek {
 		return t.month
 	}
 	return 0
 }
 
+// Week returns the receiver's ISO week number if it's at least TimeGrainWeek,
+// or zero otherwise.
+func (t GrainedTime) Week() int {
+	switch {
+	case t.grain == TimeGrainWeek:
+		return t.week
+	case t.grain > TimeGrainWeek:
+		_, week := t.Time().ISOWeek()
+		return week
+	}
+	return 0
+}
+
 // Day returns the receiver's day component if it's at least TimeGrainDay, or
 // zero otherwise.
 func (t GrainedTime) Day() int {
@@ -90,8 +112,28 @@ func (t GrainedTime) Second() int {
 	return 0
 }
 
-// Location returns the receiver's time zone location.
-func (t GrainedTime) Location() *time.Location { return t.loc }
+// Location returns the receiver's time zone location, or time.Local if none
+// was given.
+func (t GrainedTime) Location() *time.Location {
+	if t.loc == nil {
+		return time.Local
+	}
+	return t.loc
+}
+
+// In returns the time with the same wall clock components and grain as the
+// receiver, but in the given location; e.g. to re-locate a day parsed in
+// time.Local into a configured time zone.
+func (t GrainedTime) In(loc *time.Location) GrainedTime {
+	if t.grain == TimeGrainNone {
+		t.loc = loc
+		return t
+	}
+	tt := t.Time()
+	year, month, day := tt.Date()
+	hour, minute, second := tt.Clock()
+	return truncate(time.Date(year, month, day, hour, minute, second, 0, loc), t.grain)
+}
 
 // Any retruns true only if the time's grain is at least year.
 func (t GrainedTime) Any() bool {
@@ -105,6 +147,8 @@ func (t GrainedTime) Equal(other GrainedTime) bool {
 		return false
 	}
 	switch t.grain {
+	case TimeGrainWeek:
+		return other.year == t.year && other.week == t.week
 	case TimeGrainSecond:
 		if other.second != t.second {
 			return false
@@ -141,25 +185,81 @@ func (t GrainedTime) Equal(other GrainedTime) bool {
 	return true
 }
 
-// TODO func (t GrainedTime) Contains(other GrainedTime) bool
+// Contains returns true if the other time range lies entirely within the
+// receiver's range, e.g. a week contains each of its 7 days, and a day
+// contains itself. Returns false if either time has TimeGrainNone.
+func (t GrainedTime) Contains(other GrainedTime) bool {
+	if t.grain == TimeGrainNone || other.grain == TimeGrainNone {
+		return false
+	}
+	return !other.Start().Before(t.Start()) && !other.End().After(t.End())
+}
+
+// Before returns true if the receiver's time range ends at or before the
+// start of the other's. Returns false if either time has TimeGrainNone.
+func (t GrainedTime) Before(other GrainedTime) bool {
+	if t.grain == TimeGrainNone || other.grain == TimeGrainNone {
+		return false
+	}
+	return !t.End().After(other.Start())
+}
+
+// After returns true if the receiver's time range starts at or after the
+// end of the other's. Returns false if either time has TimeGrainNone.
+func (t GrainedTime) After(other GrainedTime) bool {
+	if t.grain == TimeGrainNone || other.grain == TimeGrainNone {
+		return false
+	}
+	return !t.Start().Before(other.End())
+}
+
+// Start returns the standard time that is the first instant within the
+// receiver's time range; it is the same as Time.
+func (t GrainedTime) Start() time.Time { return t.Time() }
+
+// End returns the standard time just after the receiver's time range, e.g. a
+// day grained time covers the 24 hours from its Start until its End.
+func (t GrainedTime) End() time.Time {
+	tt := t.Time()
+	switch t.grain {
+	case TimeGrainYear:
+		return tt.AddDate(1, 0, 0)
+	case TimeGrainMonth:
+		return tt.AddDate(0, 1, 0)
+	case TimeGrainWeek:
+		return tt.AddDate(0, 0, 7)
+	case TimeGrainDay:
+		return tt.AddDate(0, 0, 1)
+	case TimeGrainHour:
+		return tt.Add(time.Hour)
+	case TimeGrainMinute:
+		return tt.Add(time.Minute)
+	case TimeGrainSecond:
+		return tt.Add(time.Second)
+	}
+	return tt
+}
 
 // Time returns the standard time that is the first instant within the
 // receiver's time range.
 func (t GrainedTime) Time() time.Time {
+	loc := t.Location()
 	switch t.grain {
 	case TimeGrainNone:
 	case TimeGrainYear:
-		return time.Date(t.year, 1, 1, 0, 0, 0, 0, t.loc)
+		return time.Date(t.year, 1, 1, 0, 0, 0, 0, loc)
 	case TimeGrainMonth:
-		return time.Date(t.year, t.month, 1, 0, 0, 0, 0, t.loc)
+		return time.Date(t.year, t.month, 1, 0, 0, 0, 0, loc)
+	case TimeGrainWeek:
+		return isoWeekStart(t.year, t.week, loc)
 	case TimeGrainDay:
-		return time.Date(t.year, t.month, t.day, 0, 0, 0, 0, t.loc)
+		return time.Date(t.year, t.month, t.day, 0, 0, 0, 0, loc)
 	case TimeGrainHour:
-		return time.Date(t.year, t.month, t.day, t.hour, 0, 0, 0, t.loc)
+		return time.Date(t.year, t.month, t.day, t.hour, 0, 0, 0, loc)
 	case TimeGrainMinute:
-		return time.Date(t.year, t.month, t.day, t.hour, t.minute, 0, 0, t.loc)
+		return time.Date(t.year, t.month, t.day, t.hour, t.minute, 0, 0, loc)
 	case TimeGrainSecond:
-		return time.Date(t.year, t.month, t.day, t.hour, t.minute, t.second, 0, t.loc)
+		return time.Date(t.year, t.month, t.day, t.hour, t.minute, t.second, 0, loc)
 	}
 	return time.Time{}
 }
@@ -174,14 +274,16 @@ func (t GrainedTime) String() string {
 		return tt.Format("2006")
 	case TimeGrainMonth:
 		return tt.Format("2006-01")
+	case TimeGrainWeek:
+		return fmt.Sprintf("%04d-W%02d", t.year, t.week)
 	case TimeGrainDay:
 		return tt.Format("2006-01-02")
 	case TimeGrainHour:
-		return tt.Format("2006-01-02T15Z07")
+		return tt.Format("2006-01-02T15Z07:00")
 	case TimeGrainMinute:
-		return tt.Format("2006-01-02T15:04Z07")
+		return tt.Format("2006-01-02T15:04Z07:00")
 	case TimeGrainSecond:
-		return tt.Format("2006-01-02T15:04:05Z07")
+		return tt.Format("2006-01-02T15:04:05Z07:00")
 	}
 	return ""
 }
@@ -189,9 +291,95 @@ func (t GrainedTime) String() string {
 // Parse consumes any possible components from the left of the given string,
 // returning a finer grained time with additional components, the trimmed
 // string remnant, and true if any such components were consumed.
+// Parsing stops before any invalid component, like the day in 2020-02-31.
 func (t GrainedTime) Parse(b []byte) (sub GrainedTime, rest []byte, parsed bool) {
+	sub, rest, parsed, _ = t.parse(b)
+	return sub, rest, parsed
+}
+
+// ParseString is a string version of Parse.
+func (t GrainedTime) ParseString(s string) (sub GrainedTime, rest string, parsed bool) {
+	var restBytes []byte
+	sub, restBytes, parsed = t.Parse([]byte(s))
+	return sub, string(restBytes), parsed
+}
+
+// ParseTime parses all of s as an ISO time within loc, like 2020-09-03 or
+// 2020-W37, returning an error if s has any invalid components, or anything
+// left over. Times of day may have a zone offset suffix, like
+// 2020-09-03T12:00Z or 2020-09-03T12:00-04:00, which selects a fixed zone
+// location, unless loc has the same offset at that time.
+// If loc is nil, time.Local is used.
+func ParseTime(loc *time.Location, s string) (GrainedTime, error) {
+	t, rest, parsed, err := Time(loc, 0, 0, 0, 0, 0, 0).parse([]byte(s))
+	if err != nil {
+		return GrainedTime{}, fmt.Errorf("invalid time %q, %w", s, err)
+	}
+	if !parsed {
+		return GrainedTime{}, fmt.Errorf("invalid time %q, expected an ISO time like 2006-01-02", s)
+	}
+	t, rest = t.parseZone(rest)
+	if rest = bytes.TrimSpace(rest); len(rest) > 0 {
+		return GrainedTime{}, fmt.Errorf("invalid time %q, unexpected %q after %v", s, rest, t)
+	}
+	return t, nil
+}
+
+// parseZone consumes any zone offset suffix from the left of rest, if the
+// receiver is at least hour grained, returning the receiver within a location
+// having that offset.
+func (t GrainedTime) parseZone(rest []byte) (GrainedTime, []byte) {
+	if t.grain < TimeGrainHour || len(rest) == 0 {
+		return t, rest
+	}
+	var offset int
+	switch c := rest[0]; c {
+	case 'Z', 'z':
+		rest = rest[1:]
+	case '+', '-':
+		// one of +hh, +hhmm, or +hh:mm
+		digits := func(b []byte) (n int, ok bool) {
+			if len(b) < 2 || b[0] < '0' || '9' < b[0] || b[1] < '0' || '9' < b[1] {
+				return 0, false
+			}
+			return 10*int(b[0]-'0') + int(b[1]-'0'), true
+		}
+		hours, ok := digits(rest[1:])
+		if !ok || hours > 14 {
+			return t, rest
+		}
+		n := 3
+		next := rest[n:]
+		if len(next) > 0 && next[0] == ':' {
+			next = next[1:]
+		}
+		if minutes, ok := digits(next); ok && minutes < 60 {
+			offset = 60 * minutes
+			n = len(rest) - len(next) + 2
+		}
+		offset += 60 * 60 * hours
+		if c == '-' {
+			offset = -offset
+		}
+		rest = rest[n:]
+	default:
+		return t, rest
+	}
+	if _, locOffset := t.Time().Zone(); locOffset != offset {
+		if offset == 0 {
+			t.loc = time.UTC
+		} else {
+			t.loc = time.FixedZone("", offset)
+		}
+	}
+	return t, rest
+}
+
+// parse implements Parse, additionally returning any error from the first
+// invalid component.
+func (t GrainedTime) parse(b []byte) (sub GrainedTime, rest []byte, parsed bool, err error) {
 	if t.grain >= TimeGrainSecond {
-		return t, b, false
+		return t, b, false, nil
 	}
 	rest = bytes.TrimLeftFunc(b, unicode.IsSpace)
 
@@ -200,16 +388,29 @@ func (t GrainedTime) Parse(b []byte) (sub GrainedTime, rest []byte, parsed bool)
 		for len(next) > 0 && next[0] == ' ' {
 			next = next[1:]
 		}
+		if len(next) == 0 {
+			break
+		}
 
 		if t.grain < TimeGrainHour {
 			if next[0] == '-' || next[0] == '/' {
 				next = next[1:]
+			} else if t.grain == TimeGrainDay && (next[0] == 'T' || next[0] == 't') {
+				next = next[1:]
 			}
 		} else {
 			if next[0] == ':' {
 				next = next[1:]
 			}
 		}
+
+		// an ISO week like 2020-W37 may follow a year
+		week := false
+		if t.grain == TimeGrainYear && len(next) > 1 && (next[0] == 'W' || next[0] == 'w') {
+			next = next[1:]
+			week = true
+		}
+
 		var num int
 		i := 0
 		for i < len(next) {
@@ -223,73 +424,232 @@ func (t GrainedTime) Parse(b []byte) (sub GrainedTime, rest []byte, parsed bool)
 		if i == 0 {
 			break
 		}
-		t = t.integrate(num)
+		if week {
+			sub, err = t.integrateWeek(num)
+		} else {
+			sub, err = t.integrate(num)
+		}
+		if err != nil {
+			break
+		}
+		t = sub
 		next = next[i:]
 
 		rest = next
 		parsed = true
 	}
 
-	return t, rest, parsed
+	return t, rest, parsed, err
 }
 
-// ParseString is a string version of Parse.
-func (t GrainedTime) ParseString(s string) (sub GrainedTime, rest string, parsed bool) {
-	var restBytes []byte
-	sub, restBytes, parsed = t.Parse([]byte(s))
-	return sub, string(restBytes), parsed
+func (t GrainedTime) integrateWeek(num int) (GrainedTime, error) {
+	if n := isoWeeksIn(t.year); num == 0 || num > n {
+		return t, fmt.Errorf("invalid week %d, %04d has %d weeks", num, t.year, n)
+	}
+	t.week = num
+	t.grain = TimeGrainWeek
+	return t, nil
 }
 
-func (t GrainedTime) integrate(num int) GrainedTime {
+func (t GrainedTime) integrate(num int) (GrainedTime, error) {
 	switch t.grain {
 	case TimeGrainNone:
 		t.year = num
 
 	case TimeGrainYear:
 		if num == 0 || num > 12 {
-			break
+			return t, fmt.Errorf("invalid month %d, must be 1-12", num)
 		}
 		t.month = time.Month(num)
 
 	case TimeGrainMonth:
-		// TODO stricter max day-of-month logic
-		if num == 0 || num > 31 {
-			break
+		if n := daysIn(t.year, t.month); num == 0 || num > n {
+			return t, fmt.Errorf("invalid day %d, %04d-%02d has %d days", num, t.year, t.month, n)
 		}
 		t.day = num
 
+	case TimeGrainWeek:
+		// an ISO day of the week, Monday through Sunday
+		if num == 0 || num > 7 {
+			return t, fmt.Errorf("invalid day of week %d, must be 1-7", num)
+		}
+		year, month, day := isoWeekStart(t.year, t.week, time.UTC).AddDate(0, 0, num-1).Date()
+		t.year, t.month, t.week, t.day = year, month, 0, day
+
 	case TimeGrainDay:
-		if num > 24 {
-			break
+		if num > 23 {
+			return t, fmt.Errorf("invalid hour %d, must be 0-23", num)
 		}
 		t.hour = num
 
 	case TimeGrainHour:
 		if num > 59 {
-			break
+			return t, fmt.Errorf("invalid minute %d, must be 0-59", num)
 		}
 		t.minute = num
 
 	case TimeGrainMinute:
 		if num > 59 {
-			break
+			return t, fmt.Errorf("invalid second %d, must be 0-59", num)
 		}
 		t.second = num
 
 	}
-	t.grain++
+	if t.grain == TimeGrainMonth {
+		t.grain = TimeGrainDay // skipping over TimeGrainWeek
+	} else {
+		t.grain++
+	}
+	return t, nil
+}
+
+// daysIn returns the number of days in the given month.
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// isoWeeksIn returns the number of ISO weeks in the given week-numbering year;
+// the 28th of December is always within its last week.
+func isoWeeksIn(year int) int {
+	_, week := time.Date(year, 12, 28, 0, 0, 0, 0, time.UTC).ISOWeek()
+	return week
+}
+
+// truncate returns the time range of the given grain that contains tt, within
+// tt's location.
+func truncate(tt time.Time, grain TimeGrain) (t GrainedTime) {
+	t.grain = grain
+	t.loc = tt.Location()
+	if grain == TimeGrainWeek {
+		t.year, t.week = tt.ISOWeek()
+		return t
+	}
+	year, month, day := tt.Date()
+	hour, minute, second := tt.Clock()
+	switch grain {
+	case TimeGrainSecond:
+		t.second = second
+		fallthrough
+	case TimeGrainMinute:
+		t.minute = minute
+		fallthrough
+	case TimeGrainHour:
+		t.hour = hour
+		fallthrough
+	case TimeGrainDay:
+		t.day = day
+		fallthrough
+	case TimeGrainMonth:
+		t.month = month
+		fallthrough
+	case TimeGrainYear:
+		t.year = year
+	}
+	return t
+}
+
+// AddDays returns the time range of the same grain that contains the
+// receiver's start moved by n calendar days. Days are counted on the wall
+// clock within the receiver's Location, so stepping across a daylight saving
+// change keeps the same time of day.
+func (t GrainedTime) AddDays(n int) GrainedTime {
+	if t.grain == TimeGrainNone {
+		return t
+	}
+	return truncate(t.Time().AddDate(0, 0, n), t.grain)
+}
+
+// AddMonths returns the time range of the same grain that contains the
+// receiver's start moved by n calendar months. Unlike time.AddDate, any day
+// past the end of the resulting month is clamped to its last day, so that a
+// month after 2020-01-31 is 2020-02-29, rather than 2020-03-02.
+func (t GrainedTime) AddMonths(n int) GrainedTime {
+	if t.grain == TimeGrainNone {
+		return t
+	}
+	tt := t.Time()
+	year, month, day := tt.Date()
+	month += time.Month(n)
+	if max := daysIn(year, month); day > max {
+		day = max
+	}
+	hour, minute, second := tt.Clock()
+	return truncate(time.Date(year, month, day, hour, minute, second, 0, tt.Location()), t.grain)
+}
+
+// Next returns the adjacent time range of the same grain after the receiver,
+// e.g. the next day, or the next month.
+func (t GrainedTime) Next() GrainedTime { return t.step(1) }
+
+// Prev returns the adjacent time range of the same grain before the
+// receiver, e.g. the previous day, or the previous month.
+func (t GrainedTime) Prev() GrainedTime { return t.step(-1) }
+
+func (t GrainedTime) step(n int) GrainedTime {
+	switch t.grain {
+	case TimeGrainYear:
+		return t.AddMonths(12 * n)
+	case TimeGrainMonth:
+		return t.AddMonths(n)
+	case TimeGrainWeek:
+		return t.AddDays(7 * n)
+	case TimeGrainDay:
+		return t.AddDays(n)
+	case TimeGrainHour:
+		return t.addClock(time.Duration(n) * time.Hour)
+	case TimeGrainMinute:
+		return t.addClock(time.Duration(n) * time.Minute)
+	case TimeGrainSecond:
+		return t.addClock(time.Duration(n) * time.Second)
+	}
+	return t
+}
+
+// addClock moves the receiver by an elapsed duration, rather than along the
+// wall clock, so that daylight saving gaps are skipped; when falling back
+// would repeat the same wall time, it moves on by another duration.
+func (t GrainedTime) addClock(d time.Duration) GrainedTime {
+	tt := t.Time()
+	next := truncate(tt.Add(d), t.grain)
+	if next.Equal(t) {
+		next = truncate(tt.Add(2*d), t.grain)
+	}
+	return next
+}
+
+// isoWeekStart returns the first instant of the given ISO week, on its Monday;
+// the 4th of January is always within the first week of its year.
+func isoWeekStart(year, week int, loc *time.Location) time.Time {
+	jan4 := time.Date(year, 1, 4, 0, 0, 0, 0, loc)
+	monday := 4 - (int(jan4.Weekday())+6)%7
+	return time.Date(year, 1, monday+7*(week-1), 0, 0, 0, 0, loc)
+}
+
+// Week returns a week grained GrainedTime for the given ISO week-numbering
+// year and week number; returns the zero GrainedTime if either is not
+// positive. If loc is nil, time.Local is used.
+func Week(loc *time.Location, year, week int) (t GrainedTime) {
+	if loc == nil {
+		loc = time.Local
+	}
+	if year > 0 && week > 0 {
+		t.grain = TimeGrainWeek
+		t.year = year
+		t.week = week
+		t.loc = loc
+	}
 	return t
 }
 
 // Time returns a GrainedTime with the given components, stopping at the first
-// that isn't usable: year, month, and day must be positive, while hour, minute
-// and second must be non-negative.
+// that isn't positive. Any out of range components are normalized ala
+// time.Date, e.g. 2020-02-31 becomes 2020-03-02.
 // If loc is nil, time.Local is used.
 func Time(loc *time.Location, year int, month time.Month, day, hour, minute, second int) (t GrainedTime) {
-	t.loc = loc
 	if loc == nil {
 		loc = time.Local
 	}
+	t.loc = loc
 	if year > 0 {
 		t.grain++
 		t.year = year
@@ -297,7 +657,7 @@ func Time(loc *time.Location, year int, month time.Month, day, hour, minute, sec
 			t.grain++
 			t.month = month
 			if day > 0 {
-				t.grain++
+				t.grain = TimeGrainDay
 				t.day = day
 				if hour > 0 {
 					t.grain++
@@ -314,6 +674,8 @@ func Time(loc *time.Location, year int, month time.Month, day, hour, minute, sec
 			}
 		}
 	}
-	// TODO normalize ala time.Time
+	if t.grain > TimeGrainNone {
+		t = truncate(t.Time(), t.grain)
+	}
 	return t
 }
@@ -0,0 +1,160 @@
+package isotime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGrainedTime_week(t *testing.T) {
+	for _, tc := range []struct {
+		in     string
+		expect string
+		grain  TimeGrain
+		start  string
+		rest   string
+	}{
+		{in: "2020-W37", expect: "2020-W37", grain: TimeGrainWeek, start: "2020-09-07"},
+		{in: "2020w01 plans", expect: "2020-W01", grain: TimeGrainWeek, start: "2019-12-30", rest: " plans"},
+		{in: "2021-W53", expect: "2021", grain: TimeGrainYear, start: "2021-01-01", rest: "-W53"},
+		{in: "2020-W53", expect: "2020-W53", grain: TimeGrainWeek, start: "2020-12-28"},
+		{in: "2020-W37-4", expect: "2020-09-10", grain: TimeGrainDay, start: "2020-09-10"},
+		{in: "2020-W01-1", expect: "2019-12-30", grain: TimeGrainDay, start: "2019-12-30"},
+		{in: "2020-Wed", expect: "2020", grain: TimeGrainYear, start: "2020-01-01", rest: "-Wed"},
+	} {
+		t.Run(tc.in, func(t *testing.T) {
+			gt, rest, parsed := Time(time.UTC, 0, 0, 0, 0, 0, 0).ParseString(tc.in)
+			assert.True(t, parsed, "expected parsed")
+			assert.Equal(t, tc.expect, gt.String(), "expected string")
+			assert.Equal(t, tc.grain, gt.Grain(), "expected grain")
+			assert.Equal(t, tc.start, gt.Time().Format("2006-01-02"), "expected start")
+			assert.Equal(t, tc.rest, rest, "expected rest")
+		})
+	}
+
+	week := Week(time.UTC, 2020, 37)
+	assert.Equal(t, 37, week.Week())
+	assert.Equal(t, time.Month(0), week.Month())
+	assert.True(t, week.Equal(Week(time.UTC, 2020, 37)))
+	assert.False(t, week.Equal(Week(time.UTC, 2020, 38)))
+	assert.False(t, week.Equal(Time(time.UTC, 2020, 9, 7, 0, 0, 0)))
+	for day := 6; day <= 14; day++ {
+		d := Time(time.UTC, 2020, 9, day, 0, 0, 0)
+		assert.Equal(t, 7 <= day && day <= 13, week.Contains(d), "expected week contains %v", d)
+		assert.False(t, d.Contains(week), "expected %v to not contain week", d)
+	}
+	assert.Equal(t, 37, Time(time.UTC, 2020, 9, 13, 0, 0, 0).Week())
+	assert.True(t, week.Contains(week))
+	assert.True(t, Time(time.UTC, 2020, 0, 0, 0, 0, 0).Contains(week))
+	assert.True(t, Time(time.UTC, 2020, 9, 0, 0, 0, 0).Contains(week))
+	assert.False(t, Time(time.UTC, 2020, 9, 0, 0, 0, 0).Contains(Week(time.UTC, 2020, 40)))
+}
+
+func TestParseTime(t *testing.T) {
+	for _, tc := range []struct {
+		in     string
+		expect string
+		err    string
+	}{
+		{in: "2020-02-29", expect: "2020-02-29"},
+		{in: "2020-02-30", err: `invalid time "2020-02-30", invalid day 30, 2020-02 has 29 days`},
+		{in: "2019-02-29", err: `invalid time "2019-02-29", invalid day 29, 2019-02 has 28 days`},
+		{in: "2020-04-31", err: `invalid time "2020-04-31", invalid day 31, 2020-04 has 30 days`},
+		{in: "2020-13", err: `invalid time "2020-13", invalid month 13, must be 1-12`},
+		{in: "2020-00-01", err: `invalid time "2020-00-01", invalid month 0, must be 1-12`},
+		{in: "2020-W53", expect: "2020-W53"},
+		{in: "2021-W53", err: `invalid time "2021-W53", invalid week 53, 2021 has 52 weeks`},
+		{in: "2020-W01-8", err: `invalid time "2020-W01-8", invalid day of week 8, must be 1-7`},
+		{in: "2020-09-03T23:59:59", expect: "2020-09-03T23:59:59Z"},
+		{in: "2020-09-03T24", err: `invalid time "2020-09-03T24", invalid hour 24, must be 0-23`},
+		{in: "2020-09-03T12:60", err: `invalid time "2020-09-03T12:60", invalid minute 60, must be 0-59`},
+		{in: "2020-09-03 nope", err: `invalid time "2020-09-03 nope", unexpected "nope" after 2020-09-03`},
+		{in: "nope", err: `invalid time "nope", expected an ISO time like 2006-01-02`},
+	} {
+		t.Run(tc.in, func(t *testing.T) {
+			gt, err := ParseTime(time.UTC, tc.in)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, tc.expect, gt.String())
+			}
+		})
+	}
+
+	// parsing stops before the invalid day
+	gt, rest, parsed := Time(time.UTC, 0, 0, 0, 0, 0, 0).ParseString("2020-02-31 notes")
+	assert.True(t, parsed)
+	assert.Equal(t, "2020-02", gt.String())
+	assert.Equal(t, "-31 notes", rest)
+}
+
+func TestGrainedTime_normalize(t *testing.T) {
+	assert.Equal(t, "2020-03-02", Time(time.UTC, 2020, 2, 31, 0, 0, 0).String())
+	assert.Equal(t, "2021-01", Time(time.UTC, 2020, 13, 0, 0, 0, 0).String())
+	assert.Equal(t, "2020-09-04T01Z", Time(time.UTC, 2020, 9, 3, 25, 0, 0).String())
+	assert.Equal(t, time.Local, Time(nil, 2020, 0, 0, 0, 0, 0).Location())
+	assert.True(t, Time(time.UTC, 2020, 2, 31, 0, 0, 0).Equal(Time(time.UTC, 2020, 3, 2, 0, 0, 0)))
+}
+
+func TestGrainedTime_arithmetic(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		got    GrainedTime
+		expect string
+	}{
+		{"days", Time(time.UTC, 2020, 2, 28, 0, 0, 0).AddDays(2), "2020-03-01"},
+		{"days back", Time(time.UTC, 2020, 1, 1, 0, 0, 0).AddDays(-1), "2019-12-31"},
+		{"days keep hour", Time(time.UTC, 2020, 1, 31, 9, 0, 0).AddDays(1), "2020-02-01T09Z"},
+		{"days in month", Time(time.UTC, 2020, 1, 0, 0, 0, 0).AddDays(31), "2020-02"},
+		{"months", Time(time.UTC, 2020, 11, 15, 0, 0, 0).AddMonths(3), "2021-02-15"},
+		{"months clamp", Time(time.UTC, 2020, 1, 31, 0, 0, 0).AddMonths(1), "2020-02-29"},
+		{"months clamp back", Time(time.UTC, 2020, 3, 31, 0, 0, 0).AddMonths(-1), "2020-02-29"},
+		{"next year", Time(time.UTC, 2020, 0, 0, 0, 0, 0).Next(), "2021"},
+		{"next month", Time(time.UTC, 2020, 12, 0, 0, 0, 0).Next(), "2021-01"},
+		{"next week", Week(time.UTC, 2020, 53).Next(), "2021-W01"},
+		{"prev week", Week(time.UTC, 2021, 1).Prev(), "2020-W53"},
+		{"next day", Time(time.UTC, 2020, 2, 29, 0, 0, 0).Next(), "2020-03-01"},
+		{"prev day", Time(time.UTC, 2020, 3, 1, 0, 0, 0).Prev(), "2020-02-29"},
+		{"next hour", Time(time.UTC, 2020, 9, 3, 23, 0, 0).Next(), "2020-09-04T00Z"},
+		{"prev minute", Time(time.UTC, 2020, 9, 3, 12, 1, 0).Prev(), "2020-09-03T12:00Z"},
+		{"next second", Time(time.UTC, 2020, 9, 3, 12, 1, 59).Next(), "2020-09-03T12:02:00Z"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expect, tc.got.String())
+		})
+	}
+}
+
+func TestGrainedTime_dst(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+
+	// days step over daylight saving changes by the wall clock
+	day := Time(loc, 2020, 3, 7, 0, 0, 0)
+	assert.Equal(t, "2020-03-08", day.Next().String())
+	assert.Equal(t, "2020-03-09", day.Next().Next().String())
+	assert.Equal(t, 23*time.Hour, day.Next().End().Sub(day.Next().Start()))
+	assert.Equal(t, "2020-11-01T09-05:00", Time(loc, 2020, 10, 31, 9, 0, 0).AddDays(1).String())
+
+	// hours skip the spring forward gap, and don't repeat when falling back
+	assert.Equal(t, "2020-03-08T03-04:00", Time(loc, 2020, 3, 8, 1, 0, 0).Next().String())
+	assert.Equal(t, "2020-03-08T01-05:00", Time(loc, 2020, 3, 8, 3, 0, 0).Prev().String())
+	assert.Equal(t, "2020-11-01T02-05:00", Time(loc, 2020, 11, 1, 1, 0, 0).Next().String())
+}
+
+func TestGrainedTime_In(t *testing.T) {
+	east := time.FixedZone("east", 9*60*60)
+	west := time.FixedZone("west", -5*60*60)
+
+	day := Time(west, 2020, 7, 23, 0, 0, 0).In(east)
+	assert.Equal(t, "2020-07-23", day.String())
+	assert.Equal(t, east, day.Location())
+	assert.True(t, day.Before(Time(east, 2020, 7, 24, 0, 0, 0)))
+
+	assert.Equal(t, "2020-07-23T12:30+09:00", Time(west, 2020, 7, 23, 12, 30, 0).In(east).String())
+	assert.Equal(t, "2020-W30", Week(west, 2020, 30).In(east).String())
+	assert.Equal(t, east, GrainedTime{}.In(east).Location())
+}
@@ -0,0 +1,97 @@
+package isotime
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// Interval is a time range spanning from the start of one grained time until
+// the end of another, e.g. 2020-09-01..2020-09-07 covers all 7 days, and
+// 12:00-13:30 runs until the end of its last minute.
+type Interval struct {
+	From, To GrainedTime
+}
+
+// ParseInterval parses s as a range of two times separated by "..", like
+// 2020-09-01..2020-09-07, or as a time of day range separated by "-", like
+// 12:00-13:30. Both times are parsed as refinements of base, so a time of day
+// range needs a day grained base. A single time, like 2020-09, is parsed as
+// an interval from and to itself.
+func ParseInterval(base GrainedTime, s string) (iv Interval, err error) {
+	from, rest, parsed, err := base.parse([]byte(s))
+	if err != nil {
+		return Interval{}, fmt.Errorf("invalid interval %q, %w", s, err)
+	} else if !parsed {
+		return Interval{}, invalidInterval(s)
+	}
+	if zt, zrest := from.parseZone(rest); isIntervalEnd(rest, zrest) {
+		from, rest = zt, zrest
+	}
+	rest = bytes.TrimSpace(rest)
+	switch {
+	case len(rest) == 0:
+		return Interval{from, from}, nil
+	case bytes.HasPrefix(rest, []byte("..")):
+		rest = rest[2:]
+	case rest[0] == '-' && from.Grain() >= TimeGrainHour:
+		rest = rest[1:]
+	default:
+		return Interval{}, invalidInterval(s)
+	}
+	to, rest, parsed, err := base.parse(rest)
+	if err != nil {
+		return Interval{}, fmt.Errorf("invalid interval %q, %w", s, err)
+	}
+	to, rest = to.parseZone(rest)
+	if !parsed || len(bytes.TrimSpace(rest)) != 0 {
+		return Interval{}, invalidInterval(s)
+	}
+	iv = Interval{from, to}
+	if !iv.End().After(iv.Start()) {
+		return Interval{}, fmt.Errorf("invalid interval %q, %v is not before %v", s, from, to)
+	}
+	return iv, nil
+}
+
+// isIntervalEnd returns true if a zone offset was consumed from rest, leaving
+// zrest either empty or starting with "..". A negative offset must be
+// followed by "..", so that 12:00-13:30 is a time of day range.
+func isIntervalEnd(rest, zrest []byte) bool {
+	if len(zrest) == len(rest) {
+		return false
+	}
+	zrest = bytes.TrimSpace(zrest)
+	if bytes.HasPrefix(zrest, []byte("..")) {
+		return true
+	}
+	return len(zrest) == 0 && rest[0] != '-'
+}
+
+func invalidInterval(s string) error {
+	return fmt.Errorf("invalid interval %q, expected a range like 2020-09-01..2020-09-07, or 12:00-13:30", s)
+}
+
+// Start returns the first instant within the interval.
+func (iv Interval) Start() time.Time { return iv.From.Start() }
+
+// End returns the instant just after the interval.
+func (iv Interval) End() time.Time { return iv.To.End() }
+
+// Contains returns true if the given time range lies entirely within the
+// interval. Returns false if the time has TimeGrainNone.
+func (iv Interval) Contains(t GrainedTime) bool {
+	if t.Grain() == TimeGrainNone {
+		return false
+	}
+	return !t.Start().Before(iv.Start()) && !t.End().After(iv.End())
+}
+
+// String returns the interval as two ISO times separated by "..", or just one
+// if both ends are equal.
+func (iv Interval) String() string {
+	if iv.From.Equal(iv.To) {
+		return iv.From.String()
+	}
+	return iv.From.String() + ".." + iv.To.String()
+}
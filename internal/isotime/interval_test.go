@@ -0,0 +1,112 @@
+package isotime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrainedTime_bounds(t *testing.T) {
+	day := Time(time.UTC, 2020, 9, 3, 0, 0, 0)
+	assert.Equal(t, "2020-09-03T00:00:00Z", day.Start().Format(time.RFC3339))
+	assert.Equal(t, "2020-09-04T00:00:00Z", day.End().Format(time.RFC3339))
+	assert.Equal(t, 24*time.Hour, day.End().Sub(day.Start()))
+
+	month := Time(time.UTC, 2020, 2, 0, 0, 0, 0)
+	assert.Equal(t, "2020-02-01T00:00:00Z", month.Start().Format(time.RFC3339))
+	assert.Equal(t, "2020-03-01T00:00:00Z", month.End().Format(time.RFC3339))
+
+	assert.True(t, month.Before(day))
+	assert.False(t, month.After(day))
+	assert.True(t, day.After(month))
+	assert.False(t, day.Before(month))
+	assert.True(t, day.Before(Time(time.UTC, 2020, 9, 4, 0, 0, 0)))
+	assert.False(t, day.Before(Time(time.UTC, 2020, 9, 3, 12, 0, 0)))
+	assert.False(t, day.After(Time(time.UTC, 2020, 9, 3, 12, 0, 0)))
+	assert.False(t, day.Before(GrainedTime{}))
+	assert.False(t, GrainedTime{}.After(day))
+}
+
+func TestParseInterval(t *testing.T) {
+	zero := Time(time.UTC, 0, 0, 0, 0, 0, 0)
+	day := Time(time.UTC, 2020, 9, 3, 0, 0, 0)
+	for _, tc := range []struct {
+		name    string
+		base    GrainedTime
+		in      string
+		expect  string
+		err     string
+		within  []string
+		without []string
+	}{
+		{
+			name:    "days",
+			base:    zero,
+			in:      "2020-09-01..2020-09-07",
+			expect:  "2020-09-01..2020-09-07",
+			within:  []string{"2020-09-01", "2020-09-07", "2020-09-05T12", "2020-W36-3"},
+			without: []string{"2020-08-31", "2020-09-08", "2020-09", "2020-W36"},
+		},
+		{
+			name:    "months",
+			base:    zero,
+			in:      "2020-09 .. 2020-10",
+			expect:  "2020-09..2020-10",
+			within:  []string{"2020-09-01", "2020-10-31", "2020-09"},
+			without: []string{"2020-08-31", "2020-11-01", "2020"},
+		},
+		{
+			name:    "single",
+			base:    zero,
+			in:      "2020-09",
+			expect:  "2020-09",
+			within:  []string{"2020-09-01", "2020-09-30", "2020-09"},
+			without: []string{"2020-10-01", "2020"},
+		},
+		{
+			name:    "time of day",
+			base:    day,
+			in:      "12:00-13:30",
+			expect:  "2020-09-03T12:00Z..2020-09-03T13:30Z",
+			within:  []string{"2020-09-03T12", "2020-09-03T13:30", "2020-09-03T13:30:59"},
+			without: []string{"2020-09-03T11:59", "2020-09-03T13:31", "2020-09-03T13", "2020-09-03"},
+		},
+		{name: "backwards", base: zero, in: "2020-09-07..2020-09-01", err: `invalid interval "2020-09-07..2020-09-01", 2020-09-07 is not before 2020-09-01`},
+		{name: "no separator", base: zero, in: "2020-09 to 2020-10", err: `invalid interval "2020-09 to 2020-10", expected a range like 2020-09-01..2020-09-07, or 12:00-13:30`},
+		{name: "trailing", base: zero, in: "2020-09-01..2020-09-07 nope", err: `invalid interval "2020-09-01..2020-09-07 nope", expected a range like 2020-09-01..2020-09-07, or 12:00-13:30`},
+		{name: "empty", base: zero, in: "", err: `invalid interval "", expected a range like 2020-09-01..2020-09-07, or 12:00-13:30`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			iv, err := ParseInterval(tc.base, tc.in)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tc.expect, iv.String())
+			for _, s := range tc.within {
+				st, _, _ := zero.ParseString(s)
+				assert.True(t, iv.Contains(st), "expected %v to contain %v", iv, s)
+			}
+			for _, s := range tc.without {
+				st, _, _ := zero.ParseString(s)
+				assert.False(t, iv.Contains(st), "expected %v to not contain %v", iv, s)
+			}
+		})
+	}
+}
+
+func TestInterval_roundtrip(t *testing.T) {
+	day := Time(time.UTC, 2020, 9, 3, 0, 0, 0)
+	iv, err := ParseInterval(day, "12:00-13:30")
+	require.NoError(t, err)
+	back, err := ParseInterval(Time(time.UTC, 0, 0, 0, 0, 0, 0), iv.String())
+	require.NoError(t, err)
+	assert.Equal(t, iv.String(), back.String())
+	assert.True(t, iv.Start().Equal(back.Start()))
+	assert.True(t, iv.End().Equal(back.End()))
+}
@@ -0,0 +1,89 @@
+package isotime
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+var timeGrainNames = [...]string{
+	TimeGrainNone:   "none",
+	TimeGrainYear:   "year",
+	TimeGrainMonth:  "month",
+	TimeGrainWeek:   "week",
+	TimeGrainDay:    "day",
+	TimeGrainHour:   "hour",
+	TimeGrainMinute: "minute",
+	TimeGrainSecond: "second",
+}
+
+// String returns the grain's lower case name, like "day".
+func (tg TimeGrain) String() string {
+	if int(tg) < len(timeGrainNames) {
+		return timeGrainNames[tg]
+	}
+	return fmt.Sprintf("TimeGrain(%d)", uint(tg))
+}
+
+// MarshalText returns the grain's name, like "day".
+func (tg TimeGrain) MarshalText() ([]byte, error) {
+	if int(tg) >= len(timeGrainNames) {
+		return nil, fmt.Errorf("invalid time grain %d", uint(tg))
+	}
+	return []byte(timeGrainNames[tg]), nil
+}
+
+// UnmarshalText parses a grain name, like "day".
+func (tg *TimeGrain) UnmarshalText(b []byte) error {
+	for i, name := range timeGrainNames {
+		if string(b) == name {
+			*tg = TimeGrain(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid time grain %q, expected one of year, month, week, day, hour, minute, second, or none", b)
+}
+
+// MarshalText returns the time's ISO String, which is empty for the zero
+// GrainedTime.
+func (t GrainedTime) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText parses an ISO time with ParseTime, within the receiver's
+// location; an empty string unmarshals the zero GrainedTime.
+func (t *GrainedTime) UnmarshalText(b []byte) error {
+	if len(bytes.TrimSpace(b)) == 0 {
+		*t = GrainedTime{}
+		return nil
+	}
+	parsed, err := ParseTime(t.loc, string(b))
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// MarshalJSON returns the time's ISO String as a JSON string, or null for the
+// zero GrainedTime.
+func (t GrainedTime) MarshalJSON() ([]byte, error) {
+	if t.grain == TimeGrainNone {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.String())
+}
+
+// UnmarshalJSON parses a JSON string with UnmarshalText; null unmarshals the
+// zero GrainedTime.
+func (t *GrainedTime) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*t = GrainedTime{}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("invalid time %s, must be a JSON string", b)
+	}
+	return t.UnmarshalText([]byte(s))
+}
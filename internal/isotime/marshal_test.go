@@ -0,0 +1,73 @@
+package isotime
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrainedTime_marshal(t *testing.T) {
+	east := time.FixedZone("", 5*60*60+30*60)
+	for _, gt := range []GrainedTime{
+		{},
+		Time(time.UTC, 2020, 0, 0, 0, 0, 0),
+		Time(time.UTC, 2020, 9, 0, 0, 0, 0),
+		Week(time.UTC, 2020, 37),
+		Time(time.UTC, 2020, 9, 3, 0, 0, 0),
+		Time(time.UTC, 2020, 9, 3, 12, 0, 0),
+		Time(time.UTC, 2020, 9, 3, 12, 30, 0),
+		Time(time.UTC, 2020, 9, 3, 12, 30, 15),
+		Time(east, 2020, 9, 3, 12, 30, 0),
+	} {
+		t.Run(gt.String(), func(t *testing.T) {
+			text, err := gt.MarshalText()
+			require.NoError(t, err)
+			var back GrainedTime
+			if gt.Grain() > TimeGrainNone {
+				back = Time(time.UTC, 0, 0, 0, 0, 0, 0)
+			}
+			require.NoError(t, back.UnmarshalText(text))
+			assert.Equal(t, gt.Grain(), back.Grain(), "expected grain")
+			assert.Equal(t, gt.String(), back.String(), "expected text")
+			assert.True(t, gt.Start().Equal(back.Start()), "expected start")
+
+			data, err := json.Marshal(struct{ T GrainedTime }{gt})
+			require.NoError(t, err)
+			var val struct{ T GrainedTime }
+			require.NoError(t, json.Unmarshal(data, &val))
+			assert.Equal(t, gt.Grain(), val.T.Grain(), "expected JSON grain")
+			assert.Equal(t, gt.String(), val.T.String(), "expected JSON text")
+		})
+	}
+
+	data, err := json.Marshal([]GrainedTime{{}, Time(time.UTC, 2020, 9, 3, 12, 0, 0)})
+	require.NoError(t, err)
+	assert.Equal(t, `[null,"2020-09-03T12Z"]`, string(data))
+
+	var gt GrainedTime
+	assert.EqualError(t, gt.UnmarshalText([]byte("2020-02-30")),
+		`invalid time "2020-02-30", invalid day 30, 2020-02 has 29 days`)
+	assert.EqualError(t, json.Unmarshal([]byte(`"2020-09-03T12:00 lunch"`), &gt),
+		`invalid time "2020-09-03T12:00 lunch", unexpected "lunch" after 2020-09-03T12:00Z`)
+	assert.EqualError(t, json.Unmarshal([]byte(`20200903`), &gt),
+		`invalid time 20200903, must be a JSON string`)
+}
+
+func TestTimeGrain_marshal(t *testing.T) {
+	data, err := json.Marshal(map[string]TimeGrain{"g": TimeGrainWeek})
+	require.NoError(t, err)
+	assert.Equal(t, `{"g":"week"}`, string(data))
+
+	var val map[string]TimeGrain
+	require.NoError(t, json.Unmarshal([]byte(`{"a":"day","b":"none"}`), &val))
+	assert.Equal(t, map[string]TimeGrain{"a": TimeGrainDay, "b": TimeGrainNone}, val)
+
+	assert.EqualError(t, json.Unmarshal([]byte(`{"a":"fortnight"}`), &val),
+		`invalid time grain "fortnight", expected one of year, month, week, day, hour, minute, second, or none`)
+	assert.Equal(t, "minute", TimeGrainMinute.String())
+	_, err = TimeGrain(42).MarshalText()
+	assert.EqualError(t, err, "invalid time grain 42")
+}
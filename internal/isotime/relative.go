@@ -0,0 +1,164 @@
+package isotime
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseDate parses s as an ISO time, like 2020-10-01 or 2020-10, within the
+// location of the reference time now; otherwise s is parsed as a relative
+// expression by ParseRelative. Any invalid ISO time, like 2020-02-31, is an
+// error.
+func ParseDate(now time.Time, s string) (GrainedTime, error) {
+	t, rest, parsed, err := Time(now.Location(), 0, 0, 0, 0, 0, 0).parse([]byte(s))
+	if err != nil {
+		return GrainedTime{}, fmt.Errorf("invalid date %q, %w", s, err)
+	}
+	if parsed && len(bytes.TrimSpace(rest)) == 0 {
+		return t, nil
+	}
+	return ParseRelative(now, s)
+}
+
+// ParseRelative parses a relative date expression, resolved against the
+// reference time now. Supported expressions, matched case-insensitively:
+//
+//	today, tomorrow, yesterday   a day grained time
+//	+3d, -1w, +2m, +1y           a day offset by days, weeks, months, or years;
+//	                             clamped to the end of any shorter month
+//	monday, next monday          the next such day after today
+//	last monday                  the last such day before today
+//	this week, next week, ...    an ISO week grained time
+//	this month, last month, ...  a month grained time
+//	this year, next year, ...    a year grained time
+//	eom, eoy                     the last day of the current month or year
+//
+// Two word expressions may also be hyphenated, e.g. last-week.
+func ParseRelative(now time.Time, s string) (GrainedTime, error) {
+	loc := now.Location()
+	year, month, day := now.Date()
+	dayTime := func(year int, month time.Month, day int) GrainedTime {
+		// normalize any out of range month or day
+		tt := time.Date(year, month, day, 0, 0, 0, 0, loc)
+		year, month, day = tt.Date()
+		return Time(loc, year, month, day, 0, 0, 0)
+	}
+
+	fields := strings.Fields(strings.ToLower(s))
+	if len(fields) == 1 {
+		if i := strings.IndexByte(fields[0], '-'); i > 0 {
+			fields = []string{fields[0][:i], fields[0][i+1:]}
+		}
+	}
+	switch len(fields) {
+	case 1:
+		switch expr := fields[0]; expr {
+		case "today":
+			return dayTime(year, month, day), nil
+		case "tomorrow":
+			return dayTime(year, month, day+1), nil
+		case "yesterday":
+			return dayTime(year, month, day-1), nil
+		case "eom":
+			return dayTime(year, month+1, 0), nil
+		case "eoy":
+			return dayTime(year, 12, 31), nil
+		default:
+			if wd, ok := parseWeekday(expr); ok {
+				return dayTime(year, month, day+daysUntil(now.Weekday(), wd)), nil
+			}
+			if n, unit, ok := parseOffset(expr); ok {
+				switch unit {
+				case 'd':
+					return dayTime(year, month, day+n), nil
+				case 'w':
+					return dayTime(year, month, day+7*n), nil
+				case 'm':
+					return dayTime(year, month, day).AddMonths(n), nil
+				case 'y':
+					return dayTime(year, month, day).AddMonths(12 * n), nil
+				}
+			}
+		}
+
+	case 2:
+		sign, ok := 0, true
+		switch fields[0] {
+		case "this":
+		case "next":
+			sign = 1
+		case "last":
+			sign = -1
+		default:
+			ok = false
+		}
+		if !ok {
+			break
+		}
+		switch unit := fields[1]; unit {
+		case "week":
+			year, week := time.Date(year, month, day+7*sign, 0, 0, 0, 0, loc).ISOWeek()
+			return Week(loc, year, week), nil
+		case "month":
+			first := dayTime(year, month+time.Month(sign), 1)
+			return Time(loc, first.Year(), first.Month(), 0, 0, 0, 0), nil
+		case "year":
+			return Time(loc, year+sign, 0, 0, 0, 0, 0), nil
+		default:
+			if wd, ok := parseWeekday(unit); ok && sign != 0 {
+				if sign > 0 {
+					return dayTime(year, month, day+daysUntil(now.Weekday(), wd)), nil
+				}
+				return dayTime(year, month, day-daysUntil(wd, now.Weekday())), nil
+			}
+		}
+	}
+
+	return GrainedTime{}, fmt.Errorf("invalid date %q, expected an ISO date like 2006-01-02, or a relative one like tomorrow, +3d, or next monday", s)
+}
+
+// daysUntil returns how many days after from that the next to weekday is,
+// in the range [1, 7].
+func daysUntil(from, to time.Weekday) int {
+	n := (int(to) - int(from) + 7) % 7
+	if n == 0 {
+		n = 7
+	}
+	return n
+}
+
+// parseWeekday parses a full or 3-letter abbreviated, lower case, weekday name.
+func parseWeekday(s string) (time.Weekday, bool) {
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		name := strings.ToLower(wd.String())
+		if s == name || s == name[:3] {
+			return wd, true
+		}
+	}
+	return 0, false
+}
+
+// parseOffset parses an offset expression like +3d, returning the signed count
+// and unit byte.
+func parseOffset(s string) (n int, unit byte, ok bool) {
+	if len(s) < 3 || (s[0] != '+' && s[0] != '-') {
+		return 0, 0, false
+	}
+	unit = s[len(s)-1]
+	switch unit {
+	case 'd', 'w', 'm', 'y':
+	default:
+		return 0, 0, false
+	}
+	n, err := strconv.Atoi(s[1 : len(s)-1])
+	if err != nil || n < 0 {
+		return 0, 0, false
+	}
+	if s[0] == '-' {
+		n = -n
+	}
+	return n, unit, true
+}
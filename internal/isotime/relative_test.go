@@ -0,0 +1,86 @@
+package isotime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDate(t *testing.T) {
+	// a Thursday
+	now := time.Date(2020, 9, 3, 13, 14, 15, 0, time.UTC)
+	for _, tc := range []struct {
+		in     string
+		expect string
+		err    string
+	}{
+		{in: "2020-10-01", expect: "2020-10-01"},
+		{in: "2020-10", expect: "2020-10"},
+		{in: " 2021 ", expect: "2021"},
+		{in: "today", expect: "2020-09-03"},
+		{in: "Tomorrow", expect: "2020-09-04"},
+		{in: "yesterday", expect: "2020-09-02"},
+		{in: "+3d", expect: "2020-09-06"},
+		{in: "-3d", expect: "2020-08-31"},
+		{in: "+2w", expect: "2020-09-17"},
+		{in: "+1m", expect: "2020-10-03"},
+		{in: "+1y", expect: "2021-09-03"},
+		{in: "next monday", expect: "2020-09-07"},
+		{in: "next thu", expect: "2020-09-10"},
+		{in: "friday", expect: "2020-09-04"},
+		{in: "last monday", expect: "2020-08-31"},
+		{in: "last thursday", expect: "2020-08-27"},
+		{in: "next week", expect: "2020-W37"},
+		{in: "last week", expect: "2020-W35"},
+		{in: "next month", expect: "2020-10"},
+		{in: "last month", expect: "2020-08"},
+		{in: "next year", expect: "2021"},
+		{in: "this week", expect: "2020-W36"},
+		{in: "this month", expect: "2020-09"},
+		{in: "this year", expect: "2020"},
+		{in: "last-week", expect: "2020-W35"},
+		{in: "Next-Monday", expect: "2020-09-07"},
+		{in: "eom", expect: "2020-09-30"},
+		{in: "eoy", expect: "2020-12-31"},
+		{in: "soon", err: `invalid date "soon", expected an ISO date like 2006-01-02, or a relative one like tomorrow, +3d, or next monday`},
+		{in: "next fortnight", err: `invalid date "next fortnight", expected an ISO date like 2006-01-02, or a relative one like tomorrow, +3d, or next monday`},
+		{in: "this monday", err: `invalid date "this monday", expected an ISO date like 2006-01-02, or a relative one like tomorrow, +3d, or next monday`},
+		{in: "+3x", err: `invalid date "+3x", expected an ISO date like 2006-01-02, or a relative one like tomorrow, +3d, or next monday`},
+	} {
+		t.Run(tc.in, func(t *testing.T) {
+			gt, err := ParseDate(now, tc.in)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.expect, gt.String())
+				assert.Equal(t, time.UTC, gt.Location())
+			}
+		})
+	}
+}
+
+func TestParseDate_monthEnd(t *testing.T) {
+	for _, tc := range []struct {
+		now    time.Time
+		in     string
+		expect string
+	}{
+		{now: time.Date(2020, 1, 31, 12, 0, 0, 0, time.UTC), in: "+1m", expect: "2020-02-29"},
+		{now: time.Date(2021, 1, 31, 12, 0, 0, 0, time.UTC), in: "+1m", expect: "2021-02-28"},
+		{now: time.Date(2020, 3, 31, 12, 0, 0, 0, time.UTC), in: "-1m", expect: "2020-02-29"},
+		{now: time.Date(2020, 8, 31, 12, 0, 0, 0, time.UTC), in: "+13m", expect: "2021-09-30"},
+		{now: time.Date(2020, 2, 29, 12, 0, 0, 0, time.UTC), in: "+1y", expect: "2021-02-28"},
+		{now: time.Date(2020, 2, 29, 12, 0, 0, 0, time.UTC), in: "+4y", expect: "2024-02-29"},
+		{now: time.Date(2020, 2, 29, 12, 0, 0, 0, time.UTC), in: "-1y", expect: "2019-02-28"},
+	} {
+		t.Run(tc.now.Format("2006-01-02")+tc.in, func(t *testing.T) {
+			gt, err := ParseDate(tc.now, tc.in)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.expect, gt.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,327 @@
+// Package lineedit implements a minimal terminal line editor for interactive
+// command loops, supporting history and tab completion.
+//
+// When input is not a terminal (or raw terminal mode is unsupported), lines
+// are simply read as-is, without any prompt or editing.
+package lineedit
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Editor reads lines of user input, providing basic editing when reading
+// from a terminal.
+type Editor struct {
+	// Prompt is written before each line read from a terminal.
+	Prompt string
+
+	// Complete, if non-nil, is called to complete the word being typed
+	// before the cursor, given all line content before the cursor. It
+	// returns the offset within head that completion words replace.
+	Complete func(head string) (start int, words []string)
+
+	history []string
+	in      *bufio.Reader
+	out     io.Writer
+	term    *terminal
+	edit    bool
+
+	// line editing state
+	buf     []rune
+	pos     int
+	histAt  int
+	saved   []rune
+	lastTab bool
+}
+
+// New creates a new line editor reading from in and writing to out; line
+// editing is only enabled if in is a terminal.
+func New(in io.Reader, out io.Writer) *Editor {
+	ed := &Editor{in: bufio.NewReader(in), out: out}
+	if f, ok := in.(*os.File); ok {
+		if term, err := openTerminal(int(f.Fd())); err == nil {
+			ed.term = term
+			ed.edit = true
+		}
+	}
+	return ed
+}
+
+// History returns all lines read so far, oldest first.
+func (ed *Editor) History() []string { return ed.history }
+
+// ReadLine reads the next line of input, returning io.EOF once input ends.
+func (ed *Editor) ReadLine() (string, error) {
+	if !ed.edit {
+		return ed.readPlain()
+	}
+	if ed.term != nil {
+		if err := ed.term.makeRaw(); err != nil {
+			return "", err
+		}
+		defer ed.term.restore()
+	}
+	return ed.readEdit()
+}
+
+func (ed *Editor) readPlain() (string, error) {
+	line, err := ed.in.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	if err != nil {
+		return "", err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	ed.addHistory(line)
+	return line, nil
+}
+
+func (ed *Editor) addHistory(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	if i := len(ed.history) - 1; i >= 0 && ed.history[i] == line {
+		return
+	}
+	ed.history = append(ed.history, line)
+}
+
+// control keys
+const (
+	keyCtrlA     = 1
+	keyCtrlB     = 2
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlF     = 6
+	keyBackspace = 8
+	keyTab       = 9
+	keyLF        = 10
+	keyCtrlK     = 11
+	keyCtrlL     = 12
+	keyCR        = 13
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyEscape    = 27
+	keyDelete    = 127
+)
+
+func (ed *Editor) readEdit() (string, error) {
+	ed.buf, ed.pos = ed.buf[:0], 0
+	ed.histAt, ed.saved = len(ed.history), nil
+	ed.lastTab = false
+	ed.redraw()
+	for {
+		r, _, err := ed.in.ReadRune()
+		if err != nil {
+			if err == io.EOF && len(ed.buf) > 0 {
+				err = nil
+				break
+			}
+			return "", err
+		}
+
+		wasTab := ed.lastTab
+		ed.lastTab = false
+
+		switch r {
+		case keyCR, keyLF:
+			io.WriteString(ed.out, "\r\n")
+			line := string(ed.buf)
+			ed.addHistory(line)
+			return line, nil
+
+		case keyCtrlC:
+			io.WriteString(ed.out, "^C\r\n")
+			ed.buf, ed.pos = ed.buf[:0], 0
+
+		case keyCtrlD:
+			if len(ed.buf) == 0 {
+				io.WriteString(ed.out, "\r\n")
+				return "", io.EOF
+			}
+			ed.deleteAt(ed.pos)
+
+		case keyBackspace, keyDelete:
+			if ed.pos > 0 {
+				ed.pos--
+				ed.deleteAt(ed.pos)
+			}
+
+		case keyCtrlA:
+			ed.pos = 0
+		case keyCtrlE:
+			ed.pos = len(ed.buf)
+		case keyCtrlB:
+			ed.move(-1)
+		case keyCtrlF:
+			ed.move(1)
+		case keyCtrlK:
+			ed.buf = ed.buf[:ed.pos]
+		case keyCtrlU:
+			ed.buf = append(ed.buf[:0], ed.buf[ed.pos:]...)
+			ed.pos = 0
+		case keyCtrlW:
+			i := ed.pos
+			for i > 0 && unicode.IsSpace(ed.buf[i-1]) {
+				i--
+			}
+			for i > 0 && !unicode.IsSpace(ed.buf[i-1]) {
+				i--
+			}
+			ed.buf = append(ed.buf[:i], ed.buf[ed.pos:]...)
+			ed.pos = i
+		case keyCtrlL:
+			io.WriteString(ed.out, "\x1b[H\x1b[2J")
+		case keyCtrlP:
+			ed.recall(-1)
+		case keyCtrlN:
+			ed.recall(1)
+
+		case keyTab:
+			ed.complete(wasTab)
+			ed.lastTab = true
+
+		case keyEscape:
+			ed.readEscape()
+
+		default:
+			if unicode.IsPrint(r) {
+				ed.buf = append(ed.buf, 0)
+				copy(ed.buf[ed.pos+1:], ed.buf[ed.pos:])
+				ed.buf[ed.pos] = r
+				ed.pos++
+			}
+		}
+		ed.redraw()
+	}
+	line := string(ed.buf)
+	ed.addHistory(line)
+	return line, nil
+}
+
+// readEscape handles ANSI escape sequences for arrow and other navigation keys.
+func (ed *Editor) readEscape() {
+	r, _, err := ed.in.ReadRune()
+	if err != nil || (r != '[' && r != 'O') {
+		return
+	}
+	if r, _, err = ed.in.ReadRune(); err != nil {
+		return
+	}
+	switch r {
+	case 'A':
+		ed.recall(-1)
+	case 'B':
+		ed.recall(1)
+	case 'C':
+		ed.move(1)
+	case 'D':
+		ed.move(-1)
+	case 'H':
+		ed.pos = 0
+	case 'F':
+		ed.pos = len(ed.buf)
+	case '3': // delete, as ESC [ 3 ~
+		if r, _, _ = ed.in.ReadRune(); r == '~' && ed.pos < len(ed.buf) {
+			ed.deleteAt(ed.pos)
+		}
+	}
+}
+
+func (ed *Editor) move(delta int) {
+	if pos := ed.pos + delta; pos >= 0 && pos <= len(ed.buf) {
+		ed.pos = pos
+	}
+}
+
+func (ed *Editor) deleteAt(i int) {
+	if i < len(ed.buf) {
+		ed.buf = append(ed.buf[:i], ed.buf[i+1:]...)
+	}
+}
+
+// recall replaces the line being edited with an older (delta < 0) or newer
+// (delta > 0) history entry, keeping any new line so that it may be returned
+// to.
+func (ed *Editor) recall(delta int) {
+	i := ed.histAt + delta
+	if i < 0 || i > len(ed.history) {
+		return
+	}
+	if ed.histAt == len(ed.history) {
+		ed.saved = append(ed.saved[:0], ed.buf...)
+	}
+	ed.histAt = i
+	if i == len(ed.history) {
+		ed.buf = append(ed.buf[:0], ed.saved...)
+	} else {
+		ed.buf = append(ed.buf[:0], []rune(ed.history[i])...)
+	}
+	ed.pos = len(ed.buf)
+}
+
+// complete replaces the word before the cursor with its only completion, or
+// with the longest common prefix of all completions; if there is no common
+// prefix to add, and this is a repeated tab, all completions are listed.
+func (ed *Editor) complete(listAll bool) {
+	if ed.Complete == nil {
+		return
+	}
+	head := string(ed.buf[:ed.pos])
+	start, words := ed.Complete(head)
+	if start < 0 || start > len(head) || len(words) == 0 {
+		io.WriteString(ed.out, "\a")
+		return
+	}
+
+	word := words[0]
+	if len(words) == 1 {
+		word += " "
+	} else {
+		for _, other := range words[1:] {
+			word = commonPrefix(word, other)
+		}
+	}
+
+	if len(word) > len(head)-start {
+		tail := ed.buf[ed.pos:]
+		ed.buf = append([]rune(head[:start]+word), tail...)
+		ed.pos = len(ed.buf) - len(tail)
+	} else if listAll {
+		io.WriteString(ed.out, "\r\n")
+		for _, w := range words {
+			fmt.Fprintf(ed.out, "%v\r\n", w)
+		}
+	}
+}
+
+func commonPrefix(a, b string) string {
+	ar, br := []rune(a), []rune(b)
+	i := 0
+	for i < len(ar) && i < len(br) && ar[i] == br[i] {
+		i++
+	}
+	return string(ar[:i])
+}
+
+// redraw re-writes the prompt and line being edited, placing the cursor.
+func (ed *Editor) redraw() {
+	var sb strings.Builder
+	sb.WriteString("\r")
+	sb.WriteString(ed.Prompt)
+	sb.WriteString(string(ed.buf))
+	sb.WriteString("\x1b[K")
+	if n := len(ed.buf) - ed.pos; n > 0 {
+		fmt.Fprintf(&sb, "\x1b[%dD", n)
+	}
+	io.WriteString(ed.out, sb.String())
+}
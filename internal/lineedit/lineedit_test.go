@@ -0,0 +1,72 @@
+package lineedit
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEditor_plain(t *testing.T) {
+	ed := New(strings.NewReader("one\r\ntwo\n\nthree"), ioutil.Discard)
+	for _, expect := range []string{"one", "two", "", "three"} {
+		line, err := ed.ReadLine()
+		assert.NoError(t, err)
+		assert.Equal(t, expect, line)
+	}
+	_, err := ed.ReadLine()
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, []string{"one", "two", "three"}, ed.History())
+}
+
+func TestEditor_edit(t *testing.T) {
+	words := []string{"today", "todo", "wip"}
+	for _, tc := range []struct {
+		name   string
+		input  string
+		expect []string
+	}{
+		{"simple", "hello\r", []string{"hello"}},
+		{"backspace", "helx\x7flo\r", []string{"hello"}},
+		{"cursor movement", "hllo\x1b[D\x1b[D\x1b[De\x05!\r", []string{"hello!"}},
+		{"kill line", "nope\x15yes\r", []string{"yes"}},
+		{"kill word", "one two\x17three\r", []string{"one three"}},
+		{"history", "one\rtwo\r\x1b[A\x1b[A\r\x1b[A\x1b[B\r", []string{"one", "two", "one", ""}},
+		{"history keeps new line", "one\rtw\x1b[A\x1b[Bo\r", []string{"one", "two"}},
+		{"complete unique", "w\tx\r", []string{"wip x"}},
+		{"complete common prefix", "t\t\r", []string{"tod"}},
+		{"complete arg", "wip to\ta\t\r", []string{"wip today "}},
+		{"cancel", "nope\x03yes\r", []string{"yes"}},
+		{"eof", "one\r\x04", []string{"one"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var out strings.Builder
+			ed := New(strings.NewReader(tc.input), &out)
+			ed.edit = true
+			ed.Prompt = "> "
+			ed.Complete = func(head string) (start int, cands []string) {
+				start = strings.LastIndexByte(head, ' ') + 1
+				for _, word := range words {
+					if strings.HasPrefix(word, head[start:]) {
+						cands = append(cands, word)
+					}
+				}
+				return start, cands
+			}
+			var lines []string
+			for {
+				line, err := ed.ReadLine()
+				if err == io.EOF {
+					break
+				}
+				if !assert.NoError(t, err) {
+					break
+				}
+				lines = append(lines, line)
+			}
+			assert.Equal(t, tc.expect, lines)
+		})
+	}
+}
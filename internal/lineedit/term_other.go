@@ -0,0 +1,16 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+// +build !linux,!darwin,!dragonfly,!freebsd,!netbsd,!openbsd
+
+package lineedit
+
+import "errors"
+
+// terminal is unsupported on this platform, so line editing is never enabled.
+type terminal struct{}
+
+func openTerminal(fd int) (*terminal, error) {
+	return nil, errors.New("terminal line editing not supported")
+}
+
+func (t *terminal) makeRaw() error { return nil }
+func (t *terminal) restore() error { return nil }
@@ -0,0 +1,43 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+// +build linux darwin dragonfly freebsd netbsd openbsd
+
+package lineedit
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// terminal supports switching a terminal file descriptor into raw mode.
+type terminal struct {
+	fd   int
+	orig syscall.Termios
+}
+
+func openTerminal(fd int) (*terminal, error) {
+	t := &terminal{fd: fd}
+	if err := ioctlTermios(fd, ioctlGetTermios, &t.orig); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *terminal) makeRaw() error {
+	raw := t.orig
+	raw.Iflag &^= syscall.BRKINT | syscall.ICRNL | syscall.INPCK | syscall.ISTRIP | syscall.IXON
+	raw.Lflag &^= syscall.ECHO | syscall.ICANON | syscall.IEXTEN | syscall.ISIG
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	return ioctlTermios(t.fd, ioctlSetTermios, &raw)
+}
+
+func (t *terminal) restore() error {
+	return ioctlTermios(t.fd, ioctlSetTermios, &t.orig)
+}
+
+func ioctlTermios(fd int, req uintptr, termios *syscall.Termios) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), req, uintptr(unsafe.Pointer(termios))); errno != 0 {
+		return errno
+	}
+	return nil
+}